@@ -6,6 +6,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"image"
+	"io"
+	"math"
 	"path"
 	"strconv"
 	"strings"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/abelroes/gmtk2024/assets/levels"
 	"github.com/abelroes/gmtk2024/src/audio"
+	"github.com/abelroes/gmtk2024/src/collision"
 	"github.com/abelroes/gmtk2024/src/vector"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
@@ -36,6 +39,8 @@ type Asset struct {
 	Sounds []*mp3.Stream
 	Font   *text.GoTextFaceSource
 	Levels []levels.Level
+	// LevelPack is the pack Levels were loaded from, nil for the embedded campaign
+	LevelPack *LevelPack
 
 	Credits string
 }
@@ -113,6 +118,12 @@ func New() (*Asset, error) {
 	}, nil
 }
 
+// UseLevelPack replaces the embedded campaign with the pack's levels
+func (asset *Asset) UseLevelPack(pack *LevelPack) {
+	asset.LevelPack = pack
+	asset.Levels = pack.Levels
+}
+
 func (asset *Asset) GetImage(index ImageIndex) *ebiten.Image {
 	return asset.Images[index]
 }
@@ -169,14 +180,23 @@ func readLevels() ([]levels.Level, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+
+	return ParseLevels(f)
+}
 
+// ParseLevels reads every object group of a Tiled map as a level, in the order they appear in the file
+func ParseLevels(r io.Reader) ([]levels.Level, error) {
 	var tmxMap levels.Map
-	xml.NewDecoder(f).Decode(&tmxMap)
+	if err := xml.NewDecoder(r).Decode(&tmxMap); err != nil {
+		return nil, err
+	}
 
 	lvls := make([]levels.Level, len(tmxMap.ObjectGroups))
 
 	for i, group := range tmxMap.ObjectGroups {
 		lvl := &lvls[i]
+		lvl.Name = group.Name
 
 		playerObj := group.FindObjectByName("player")
 		if playerObj == nil {
@@ -187,11 +207,44 @@ func readLevels() ([]levels.Level, error) {
 			return nil, fmt.Errorf("goal not found in %s", group.Name)
 		}
 
-		lvl.PlayerStartPos = playerObj.CenterPos()
+		// levels are laid out relative to the "screen" object, the part of the map the camera shows
+		offset := group.Offset()
+		origin := vector.New(0, 0)
+		if screenObj := group.FindObjectByName("screen"); screenObj != nil {
+			origin = screenObj.TopLeftPos()
+			origin.Add(offset)
+		}
+		levelPos := func(pos vector.Vector2) vector.Vector2 {
+			pos.Add(offset)
+			return pos.SubOut(origin)
+		}
+
+		lvl.PlayerStartPos = levelPos(playerObj.CenterPos())
+		// the rocket image points right, Tiled rotates clockwise while Rot goes counterclockwise
+		lvl.PlayerStartRot = -playerObj.RotationRad()
+		if playerObj.FlippedHorizontally() {
+			lvl.PlayerStartRot += math.Pi
+		}
 
-		lvl.GoalPos = goalObj.CenterPos()
+		lvl.GoalPos = levelPos(goalObj.CenterPos())
 
 		for _, obj := range group.Objects {
+			vertices, closed, err := obj.ShapeVertices()
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing shape %s of %s: %w", obj.Id, group.Name, err)
+			}
+			if len(vertices) >= 2 {
+				for i := range vertices {
+					vertices[i] = levelPos(vertices[i])
+				}
+				if closed {
+					if err := collision.ValidatePolygon(vertices); err != nil {
+						return nil, fmt.Errorf("invalid shape %s of %s: %w", obj.Id, group.Name, err)
+					}
+				}
+				lvl.Obstacles = append(lvl.Obstacles, levels.ObstacleInfo{Vertices: vertices, Closed: closed})
+			}
+
 			if obj.Name == "pipe" {
 
 				movement, err := getWallMovementFromObj(obj)
@@ -199,10 +252,18 @@ func readLevels() ([]levels.Level, error) {
 					return nil, err
 				}
 
+				rotation, err := getWallRotationFromObj(obj)
+				if err != nil {
+					return nil, err
+				}
+
 				wall := levels.WallInfo{
 					W:        obj.Width,
 					H:        obj.Height,
-					Pos:      obj.TopLeftPos(),
+					Pos:      levelPos(obj.TopLeftPos()),
+					Rotation: rotation,
+					FlipH:    obj.FlippedHorizontally(),
+					FlipV:    obj.FlippedVertically(),
 					Movement: movement,
 				}
 				lvl.Walls = append(lvl.Walls, wall)
@@ -213,6 +274,24 @@ func readLevels() ([]levels.Level, error) {
 	return lvls, nil
 }
 
+/*
+getWallRotationFromObj adds the optional "rotation" property, in degrees, to
+the Tiled rotation. Unlike the Tiled one it turns the wall in place, around
+its center
+*/
+func getWallRotationFromObj(obj levels.Object) (float64, error) {
+	rotation := obj.RotationRad()
+	if obj.Props == nil || obj.Props.GetProp("rotation") == nil {
+		return rotation, nil
+	}
+
+	degrees, err := obj.Props.GetPropFloat("rotation")
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing rotation of %s: %w", obj.Id, err)
+	}
+	return rotation + degrees*math.Pi/180, nil
+}
+
 func getWallMovementFromObj(obj levels.Object) (*levels.WallMovementInfo, error) {
 	if obj.Props == nil {
 		return nil, nil
@@ -310,5 +389,8 @@ func readPlayerPolygon(playerImg image.Image) ([]vector.Vector2, error) {
 		polygons = append(polygons, vector.New(x-imgW*.5, y-imgH*.5))
 	}
 
+	if err := collision.ValidatePolygon(polygons); err != nil {
+		return nil, fmt.Errorf("invalid player hitbox: %w", err)
+	}
 	return polygons, nil
 }
@@ -0,0 +1,123 @@
+package assets
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abelroes/gmtk2024/assets/levels"
+)
+
+const packManifestName = "pack.json"
+
+// PackManifest is the optional pack.json at the root of a level pack
+type PackManifest struct {
+	Title  string
+	Author string
+	// Order lists the pack's TMX files in the order they are played, every TMX file is played sorted by name when empty
+	Order []string
+}
+
+// LevelPack is a set of levels loaded from disk that replaces the embedded campaign
+type LevelPack struct {
+	Title  string
+	Author string
+	Levels []levels.Level
+}
+
+/*
+LoadLevelPack reads levels from path, which is either a single TMX file, a
+directory of them or a zip archive of them. Every object group of every TMX
+file is a level, and a pack.json at the root of a directory or zip may give
+the pack's title, author and file order
+*/
+func LoadLevelPack(packPath string) (*LevelPack, error) {
+	info, err := os.Stat(packPath)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSuffix(filepath.Base(packPath), filepath.Ext(packPath))
+
+	var pack *LevelPack
+	switch {
+	case info.IsDir():
+		pack, err = readLevelPack(os.DirFS(packPath), title)
+	case strings.EqualFold(filepath.Ext(packPath), ".zip"):
+		var archive *zip.ReadCloser
+		archive, err = zip.OpenReader(packPath)
+		if err != nil {
+			break
+		}
+		defer archive.Close()
+		pack, err = readLevelPack(archive, title)
+	default:
+		pack = &LevelPack{Title: title}
+		pack.Levels, err = readLevelFile(os.DirFS(filepath.Dir(packPath)), filepath.Base(packPath))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("level pack %s: %w", packPath, err)
+	}
+	if len(pack.Levels) == 0 {
+		return nil, fmt.Errorf("level pack %s has no levels", packPath)
+	}
+	return pack, nil
+}
+
+func readLevelPack(fsys fs.FS, title string) (*LevelPack, error) {
+	manifest := PackManifest{Title: title}
+
+	data, err := fs.ReadFile(fsys, packManifestName)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %w", packManifestName, err)
+		}
+		if manifest.Title == "" {
+			manifest.Title = title
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	files := manifest.Order
+	if len(files) == 0 {
+		files, err = fs.Glob(fsys, "*.tmx")
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	pack := &LevelPack{Title: manifest.Title, Author: manifest.Author}
+	for _, name := range files {
+		lvls, err := readLevelFile(fsys, path.Clean(name))
+		if err != nil {
+			return nil, err
+		}
+		pack.Levels = append(pack.Levels, lvls...)
+	}
+	return pack, nil
+}
+
+func readLevelFile(fsys fs.FS, name string) ([]levels.Level, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lvls, err := ParseLevels(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return lvls, nil
+}
@@ -13,13 +13,28 @@ type WallMovementInfo struct {
 }
 
 type WallInfo struct {
-	W, H     float64
-	Pos      vector.Vector2
-	Movement *WallMovementInfo
+	W, H float64
+	// Pos is the top left corner of the wall before it is rotated around its center
+	Pos vector.Vector2
+	// Rotation is in radians, clockwise around the wall's center as in Tiled
+	Rotation     float64
+	FlipH, FlipV bool
+	Movement     *WallMovementInfo
+}
+
+// ObstacleInfo is a Tiled polygon, or an open polyline when Closed is false
+type ObstacleInfo struct {
+	Vertices []vector.Vector2
+	Closed   bool
 }
 
 type Level struct {
+	// Name is the name of the Tiled object group the level comes from
+	Name           string
 	PlayerStartPos vector.Vector2
+	// PlayerStartRot is the player's initial Rot, counterclockwise in radians
+	PlayerStartRot float64
 	GoalPos        vector.Vector2
 	Walls          []WallInfo
+	Obstacles      []ObstacleInfo
 }
@@ -2,7 +2,9 @@ package levels
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/abelroes/gmtk2024/src/vector"
 )
@@ -14,18 +16,63 @@ type Map struct {
 type ObjectGroup struct {
 	Id      string   `xml:"id,attr"`
 	Name    string   `xml:"name,attr"`
+	OffsetX float64  `xml:"offsetx,attr"`
+	OffsetY float64  `xml:"offsety,attr"`
 	Objects []Object `xml:"object"`
 }
 
+// the highest bits of a gid tell how the tile is flipped
+const (
+	flippedHorizontallyFlag = 0x80000000
+	flippedVerticallyFlag   = 0x40000000
+	flippedDiagonallyFlag   = 0x20000000
+	flipFlags               = flippedHorizontallyFlag | flippedVerticallyFlag | flippedDiagonallyFlag
+)
+
 type Object struct {
 	Id   string `xml:"id,attr"`
 	Name string `xml:"name,attr"`
+	// Gid is set on tile objects only
+	Gid uint32 `xml:"gid,attr"`
 
 	X      float64 `xml:"x,attr"`
 	Y      float64 `xml:"y,attr"`
 	Width  float64 `xml:"width,attr"`
 	Height float64 `xml:"height,attr"`
-	Props  *Props  `xml:"properties"`
+	// Rotation is in degrees, clockwise around the object's anchor
+	Rotation float64 `xml:"rotation,attr"`
+	Props    *Props  `xml:"properties"`
+
+	// Polygon and Polyline are set on shape objects, their points are relative to X and Y
+	Polygon  *Points `xml:"polygon"`
+	Polyline *Points `xml:"polyline"`
+}
+
+type Points struct {
+	Points string `xml:"points,attr"`
+}
+
+// Parse reads Tiled's "x1,y1 x2,y2 ..." point lists
+func (points *Points) Parse() ([]vector.Vector2, error) {
+	fields := strings.Fields(points.Points)
+	vertices := make([]vector.Vector2, 0, len(fields))
+	for _, field := range fields {
+		xStr, yStr, found := strings.Cut(field, ",")
+		if !found {
+			return nil, fmt.Errorf("failed parsing point %q", field)
+		}
+
+		x, err := strconv.ParseFloat(xStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(yStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		vertices = append(vertices, vector.New(x, y))
+	}
+	return vertices, nil
 }
 
 type Props struct {
@@ -47,12 +94,85 @@ func (group *ObjectGroup) FindObjectByName(name string) *Object {
 	return nil
 }
 
-func (object Object) TopLeftPos() vector.Vector2 {
-	return vector.New(object.X, object.Y-object.Height)
+// Offset is how far Tiled draws the group's objects from their x and y
+func (group *ObjectGroup) Offset() vector.Vector2 {
+	return vector.New(group.OffsetX, group.OffsetY)
+}
+
+func (object Object) IsTile() bool {
+	return object.Gid != 0
+}
+
+// Tile is the gid without its flip flags
+func (object Object) Tile() uint32 {
+	return object.Gid &^ flipFlags
+}
+
+func (object Object) FlippedHorizontally() bool {
+	return object.Gid&flippedHorizontallyFlag != 0
+}
+
+func (object Object) FlippedVertically() bool {
+	return object.Gid&flippedVerticallyFlag != 0
+}
+
+// RotationRad is Rotation in radians, still clockwise
+func (object Object) RotationRad() float64 {
+	return object.Rotation * math.Pi / 180
 }
 
+/*
+CenterPos is the center of the object as drawn by Tiled: tile objects are
+anchored by their bottom left corner and every other object by its top left
+one, and they rotate around that anchor
+*/
 func (object Object) CenterPos() vector.Vector2 {
-	return vector.New(object.X+object.Width/2, object.Y-object.Height/2)
+	halfH := object.Height / 2
+	if object.IsTile() {
+		halfH = -halfH
+	}
+
+	sin, cos := math.Sincos(object.RotationRad())
+	halfW := object.Width / 2
+	return vector.New(
+		object.X+halfW*cos-halfH*sin,
+		object.Y+halfW*sin+halfH*cos,
+	)
+}
+
+/*
+ShapeVertices returns the points of a polygon or polyline object, rotated
+around the object's position, and whether the shape is closed. It returns nil
+for any other object
+*/
+func (object Object) ShapeVertices() ([]vector.Vector2, bool, error) {
+	points, closed := object.Polyline, false
+	if object.Polygon != nil {
+		points, closed = object.Polygon, true
+	}
+	if points == nil {
+		return nil, false, nil
+	}
+
+	vertices, err := points.Parse()
+	if err != nil {
+		return nil, false, err
+	}
+
+	sin, cos := math.Sincos(object.RotationRad())
+	for i, vertex := range vertices {
+		vertices[i] = vector.New(
+			object.X+vertex.X*cos-vertex.Y*sin,
+			object.Y+vertex.X*sin+vertex.Y*cos,
+		)
+	}
+	return vertices, closed, nil
+}
+
+// TopLeftPos is the top left corner of the object before it is rotated around its center
+func (object Object) TopLeftPos() vector.Vector2 {
+	center := object.CenterPos()
+	return vector.New(center.X-object.Width/2, center.Y-object.Height/2)
 }
 
 func (props *Props) GetProp(name string) *Property {
@@ -39,6 +39,7 @@ type Manager struct {
 	ctx          *audio.Context
 	audioPlayers []*audio.Player
 	rng          *rand.Rand
+	paused       []*audio.Player
 }
 
 func (manager *Manager) createAudioPlayer(audio *mp3.Stream) (*audio.Player, error) {
@@ -111,6 +112,36 @@ func (manager *Manager) IsPlayingWinTrack() bool {
 	return manager.audioPlayers[WinTrackIndex].IsPlaying()
 }
 
+// PauseAll pauses every sound currently playing, ResumeAll picks them up where they stopped
+func (manager *Manager) PauseAll() {
+	for _, player := range manager.audioPlayers {
+		if player.IsPlaying() {
+			player.Pause()
+			manager.paused = append(manager.paused, player)
+		}
+	}
+}
+
+func (manager *Manager) ResumeAll() {
+	for _, player := range manager.paused {
+		player.Play()
+	}
+	manager.paused = manager.paused[:0]
+}
+
+/*
+StopPaused drops the sound effects PauseAll paused, so they don't start again
+over the menus, and only resumes the soundtracks
+*/
+func (manager *Manager) StopPaused() {
+	for _, player := range manager.paused {
+		if player == manager.audioPlayers[SoundTrackIndex] || player == manager.audioPlayers[WinTrackIndex] {
+			player.Play()
+		}
+	}
+	manager.paused = manager.paused[:0]
+}
+
 func (manager *Manager) SetVolumes(volume settings.SettingsVolume) {
 	for key, player := range manager.audioPlayers {
 		isSoundTrack := SoundIndex(key) == SoundTrackIndex || SoundIndex(key) == WinTrackIndex
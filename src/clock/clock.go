@@ -0,0 +1,36 @@
+package clock
+
+import "time"
+
+// TicksPerSecond is the fixed simulation rate, one tick per ebiten Update
+const TicksPerSecond = 60
+
+type Tick uint64
+
+type Clock struct {
+	now Tick
+}
+
+func New() *Clock {
+	return &Clock{}
+}
+
+func (clock *Clock) Step() {
+	clock.now++
+}
+
+func (clock *Clock) Now() Tick {
+	return clock.now
+}
+
+func (clock *Clock) Since(start Tick) Tick {
+	return clock.now - start
+}
+
+func FromDuration(d time.Duration) Tick {
+	return Tick(d * TicksPerSecond / time.Second)
+}
+
+func (tick Tick) Duration() time.Duration {
+	return time.Duration(tick) * time.Second / TicksPerSecond
+}
@@ -1,21 +1,105 @@
 package collision
 
-import "github.com/abelroes/gmtk2024/src/vector"
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
 
 type CollisionRect struct {
+	// Pos is the top left corner before the rect is rotated
 	Pos vector.Vector2
 	W   float64
 	H   float64
+	// Rotation is in radians, clockwise on screen around the rect's center
+	Rotation float64
+}
+
+func (rect CollisionRect) Center() vector.Vector2 {
+	return vector.New(rect.Pos.X+rect.W/2, rect.Pos.Y+rect.H/2)
+}
+
+// Vertices are the rect's corners once rotated, clockwise from the top left one
+func (rect CollisionRect) Vertices() []vector.Vector2 {
+	center := rect.Center()
+	sin, cos := math.Sincos(rect.Rotation)
+	halfW, halfH := rect.W/2, rect.H/2
+
+	corners := [4][2]float64{{-halfW, -halfH}, {halfW, -halfH}, {halfW, halfH}, {-halfW, halfH}}
+	vertices := make([]vector.Vector2, len(corners))
+	for i, corner := range corners {
+		vertices[i] = vector.New(
+			center.X+corner[0]*cos-corner[1]*sin,
+			center.Y+corner[0]*sin+corner[1]*cos,
+		)
+	}
+	return vertices
 }
 
 type CollisionPolygon struct {
 	Vertices []vector.Vector2
 }
 
-func lineLine(x1, y1, x2, y2, x3, y3, x4, y4 float64) bool {
+type CollisionCircle struct {
+	Center vector.Vector2
+	Radius float64
+}
+
+// Polygon is the rect's rotated corners as a polygon
+func (rect CollisionRect) Polygon() CollisionPolygon {
+	return CollisionPolygon{Vertices: rect.Vertices()}
+}
+
+/*
+lineLineParams returns how far along each line they cross, as a fraction of
+the first and of the second line. Parallel lines, collinear ones included,
+don't cross at a single point: parallel is then true and the fractions 0,
+see collinearOverlap
+*/
+func lineLineParams(x1, y1, x2, y2, x3, y3, x4, y4 float64) (uA, uB float64, parallel bool) {
+	denominator := (y4-y3)*(x2-x1) - (x4-x3)*(y2-y1)
+	if denominator == 0 {
+		return 0, 0, true
+	}
+
 	// calculate the direction of the lines
-	uA := ((x4-x3)*(y1-y3) - (y4-y3)*(x1-x3)) / ((y4-y3)*(x2-x1) - (x4-x3)*(y2-y1))
-	uB := ((x2-x1)*(y1-y3) - (y2-y1)*(x1-x3)) / ((y4-y3)*(x2-x1) - (x4-x3)*(y2-y1))
+	uA = ((x4-x3)*(y1-y3) - (y4-y3)*(x1-x3)) / denominator
+	uB = ((x2-x1)*(y1-y3) - (y2-y1)*(x1-x3)) / denominator
+	return uA, uB, false
+}
+
+/*
+collinearOverlap handles the parallel lines lineLineParams gives up on. When
+the second line lies on the first one and they overlap it returns the first
+fraction of the first line covered by the second one
+*/
+func collinearOverlap(x1, y1, x2, y2, x3, y3, x4, y4 float64) (float64, bool) {
+	dx, dy := x2-x1, y2-y1
+	lengthSq := dx*dx + dy*dy
+
+	// the first line is a single point, overlapping when it lies between the ends of the second one
+	if lengthSq == 0 {
+		onLine := (x4-x3)*(y1-y3)-(y4-y3)*(x1-x3) == 0
+		between := x1 >= min(x3, x4) && x1 <= max(x3, x4) && y1 >= min(y3, y4) && y1 <= max(y3, y4)
+		return 0, onLine && between
+	}
+
+	if dx*(y3-y1)-dy*(x3-x1) != 0 || dx*(y4-y1)-dy*(x4-x1) != 0 {
+		return 0, false
+	}
+
+	t3 := ((x3-x1)*dx + (y3-y1)*dy) / lengthSq
+	t4 := ((x4-x1)*dx + (y4-y1)*dy) / lengthSq
+	start, end := max(0, min(t3, t4)), min(1, max(t3, t4))
+	return start, start <= end
+}
+
+func lineLine(x1, y1, x2, y2, x3, y3, x4, y4 float64) bool {
+	uA, uB, parallel := lineLineParams(x1, y1, x2, y2, x3, y3, x4, y4)
+	if parallel {
+		_, overlap := collinearOverlap(x1, y1, x2, y2, x3, y3, x4, y4)
+		return overlap
+	}
 
 	// if uA and uB are between 0-1, lines are colliding
 	if uA >= 0 && uA <= 1 && uB >= 0 && uB <= 1 {
@@ -40,8 +124,61 @@ func lineRect(x1, y1, x2, y2, rx, ry, rw, rh float64) bool {
 	return false
 }
 
-// Source: https://www.jeffreythompson.org/collision-detection/poly-rect.php
+func linePolygon(x1, y1, x2, y2 float64, vertices []vector.Vector2) bool {
+	for current := range vertices {
+		next := (current + 1) % len(vertices)
+		if lineLine(x1, y1, x2, y2, vertices[current].X, vertices[current].Y, vertices[next].X, vertices[next].Y) {
+			return true
+		}
+	}
+	return false
+}
+
+// PointInRect works in the rect's own frame, so rotated rects are handled too
+func PointInRect(point vector.Vector2, rect CollisionRect) bool {
+	if rect.Rotation != 0 {
+		center := rect.Center()
+		sin, cos := math.Sincos(-rect.Rotation)
+		dx, dy := point.X-center.X, point.Y-center.Y
+		point = vector.New(center.X+dx*cos-dy*sin, center.Y+dx*sin+dy*cos)
+	}
+
+	return point.X >= rect.Pos.X && point.X <= rect.Pos.X+rect.W &&
+		point.Y >= rect.Pos.Y && point.Y <= rect.Pos.Y+rect.H
+}
+
+/*
+PointInPolygon casts a horizontal ray from point and counts the edges it
+crosses, the point is inside when that count is odd. Works for concave
+polygons too
+Source: https://www.jeffreythompson.org/collision-detection/poly-point.php
+*/
+func PointInPolygon(point vector.Vector2, polygon CollisionPolygon) bool {
+	inside := false
+	for current := range polygon.Vertices {
+		next := (current + 1) % len(polygon.Vertices)
+		vc, vn := polygon.Vertices[current], polygon.Vertices[next]
+
+		if (vc.Y > point.Y) != (vn.Y > point.Y) &&
+			point.X < (vn.X-vc.X)*(point.Y-vc.Y)/(vn.Y-vc.Y)+vc.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+/*
+HasCollidedRectPolygon reports whether rect and polygon overlap: either an
+edge of polygon crosses rect, rotated rects being checked against their
+rotated edges, or one of them is entirely inside the other
+Source: https://www.jeffreythompson.org/collision-detection/poly-rect.php
+*/
 func HasCollidedRectPolygon(rect CollisionRect, polygon CollisionPolygon) bool {
+	var rectVertices []vector.Vector2
+	if rect.Rotation != 0 {
+		rectVertices = rect.Vertices()
+	}
+
 	next := 0
 	verticesQtd := len(polygon.Vertices)
 
@@ -54,7 +191,12 @@ func HasCollidedRectPolygon(rect CollisionRect, polygon CollisionPolygon) bool {
 		currentVec := polygon.Vertices[current]
 		nextVec := polygon.Vertices[next]
 
-		collision := lineRect(currentVec.X, currentVec.Y, nextVec.X, nextVec.Y, rect.Pos.X, rect.Pos.Y, rect.W, rect.H)
+		var collision bool
+		if rectVertices != nil {
+			collision = linePolygon(currentVec.X, currentVec.Y, nextVec.X, nextVec.Y, rectVertices)
+		} else {
+			collision = lineRect(currentVec.X, currentVec.Y, nextVec.X, nextVec.Y, rect.Pos.X, rect.Pos.Y, rect.W, rect.H)
+		}
 
 		if collision {
 			return true
@@ -62,5 +204,73 @@ func HasCollidedRectPolygon(rect CollisionRect, polygon CollisionPolygon) bool {
 
 	}
 
-	return false
+	// no edge crosses, so they overlap only when one contains the other
+	if verticesQtd == 0 {
+		return false
+	}
+	if PointInRect(polygon.Vertices[0], rect) {
+		return true
+	}
+
+	rectCorner := rect.Pos
+	if rectVertices != nil {
+		rectCorner = rectVertices[0]
+	}
+	return PointInPolygon(rectCorner, polygon)
+}
+
+// closestOnSegment is the point of the segment from a to b closest to point
+func closestOnSegment(point, a, b vector.Vector2) vector.Vector2 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return a
+	}
+
+	t := ((point.X-a.X)*dx + (point.Y-a.Y)*dy) / lengthSq
+	t = max(0, min(1, t))
+	return vector.New(a.X+t*dx, a.Y+t*dy)
+}
+
+func PointInCircle(point vector.Vector2, circle CollisionCircle) bool {
+	return point.Distance(circle.Center) <= circle.Radius
+}
+
+/*
+HasCollidedCirclePolygon reports whether circle and polygon overlap: either an
+edge of polygon comes within the circle's radius, which also covers polygons
+entirely inside the circle, or the circle is entirely inside polygon
+Source: https://www.jeffreythompson.org/collision-detection/poly-circle.php
+*/
+func HasCollidedCirclePolygon(circle CollisionCircle, polygon CollisionPolygon) bool {
+	verticesQtd := len(polygon.Vertices)
+	for current := 0; current < verticesQtd; current++ {
+		next := (current + 1) % verticesQtd
+		closest := closestOnSegment(circle.Center, polygon.Vertices[current], polygon.Vertices[next])
+		if PointInCircle(closest, circle) {
+			return true
+		}
+	}
+
+	if verticesQtd < 3 {
+		return false
+	}
+	return PointInPolygon(circle.Center, polygon)
+}
+
+// HasCollidedCircleRect clamps the circle's center to the rect in the rect's own frame, so rotated rects are handled too
+func HasCollidedCircleRect(circle CollisionCircle, rect CollisionRect) bool {
+	center := circle.Center
+	if rect.Rotation != 0 {
+		rectCenter := rect.Center()
+		sin, cos := math.Sincos(-rect.Rotation)
+		dx, dy := center.X-rectCenter.X, center.Y-rectCenter.Y
+		center = vector.New(rectCenter.X+dx*cos-dy*sin, rectCenter.Y+dx*sin+dy*cos)
+	}
+
+	closest := vector.New(
+		max(rect.Pos.X, min(rect.Pos.X+rect.W, center.X)),
+		max(rect.Pos.Y, min(rect.Pos.Y+rect.H, center.Y)),
+	)
+	return closest.Distance(center) <= circle.Radius
 }
@@ -0,0 +1,188 @@
+package collision
+
+import (
+	"math"
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+func polygon(points ...float64) CollisionPolygon {
+	vertices := make([]vector.Vector2, 0, len(points)/2)
+	for i := 0; i+1 < len(points); i += 2 {
+		vertices = append(vertices, vector.New(points[i], points[i+1]))
+	}
+	return CollisionPolygon{Vertices: vertices}
+}
+
+func circle(x, y, radius float64) CollisionCircle {
+	return CollisionCircle{Center: vector.New(x, y), Radius: radius}
+}
+
+var (
+	testRect = CollisionRect{Pos: vector.New(0, 0), W: 100, H: 50}
+	// a 100x10 rect turned upright around its center (50, 5), covering x 45 to 55 and y -45 to 55
+	testRotatedRect = CollisionRect{Pos: vector.New(0, 0), W: 100, H: 10, Rotation: math.Pi / 2}
+	// a U opening downwards, its notch covers x 30 to 70 and y 30 to 100
+	testConcave = polygon(0, 0, 100, 0, 100, 100, 70, 100, 70, 30, 30, 30, 30, 100, 0, 100)
+)
+
+func TestHasCollidedRectPolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		rect    CollisionRect
+		polygon CollisionPolygon
+		want    bool
+	}{
+		{"edge crossing", testRect, polygon(50, 25, 150, 25, 150, 100), true},
+		{"polygon inside rect", testRect, polygon(10, 10, 20, 10, 20, 20, 10, 20), true},
+		{"rect inside polygon", testRect, polygon(-10, -10, 200, -10, 200, 200, -10, 200), true},
+		{"rotated rect crossing", testRotatedRect, polygon(0, -40, 100, -40, 100, -30, 0, -30), true},
+		{"inside rotated rect", testRotatedRect, polygon(47, 40, 53, 40, 53, 50, 47, 50), true},
+		{"outside rotated rect, inside unrotated", testRotatedRect, polygon(0, 0, 10, 0, 10, 8, 0, 8), false},
+		{"rotated rect inside polygon", testRotatedRect, polygon(0, -100, 100, -100, 100, 100, 0, 100), true},
+		{"rect in concave notch", CollisionRect{Pos: vector.New(40, 50), W: 20, H: 20}, testConcave, false},
+		{"rect across concave arm", CollisionRect{Pos: vector.New(20, 50), W: 20, H: 20}, testConcave, true},
+		{"rect inside concave arm", CollisionRect{Pos: vector.New(5, 50), W: 20, H: 20}, testConcave, true},
+		{"touching edge", testRect, polygon(100, 0, 150, 0, 150, 50, 100, 50), true},
+		{"touching corner", testRect, polygon(100, 50, 150, 50, 150, 100, 100, 100), true},
+		{"disjoint", testRect, polygon(200, 200, 250, 200, 250, 250), false},
+		{"disjoint, bounds overlapping", testRect, polygon(85, 70, 130, 25, 130, 70), false},
+		{"empty polygon", testRect, CollisionPolygon{}, false},
+		{"single vertex inside", testRect, polygon(50, 25), true},
+		{"single vertex outside", testRect, polygon(150, 25), false},
+		{"segment crossing", testRect, polygon(-10, 25, 110, 25), true},
+		{"segment outside", testRect, polygon(-10, 60, 110, 60), false},
+		{"zero area polygon crossing", testRect, polygon(-10, 25, 50, 25, 110, 25), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasCollidedRectPolygon(test.rect, test.polygon); got != test.want {
+				t.Errorf("HasCollidedRectPolygon(%+v, %v) = %v, want %v", test.rect, test.polygon.Vertices, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPointInRect(t *testing.T) {
+	tests := []struct {
+		name  string
+		point vector.Vector2
+		rect  CollisionRect
+		want  bool
+	}{
+		{"inside", vector.New(50, 25), testRect, true},
+		{"on edge", vector.New(100, 25), testRect, true},
+		{"on corner", vector.New(0, 0), testRect, true},
+		{"outside", vector.New(101, 25), testRect, false},
+		{"negative", vector.New(-1, -1), testRect, false},
+		{"inside rotated", vector.New(50, -40), testRotatedRect, true},
+		{"outside rotated, inside unrotated", vector.New(10, 5), testRotatedRect, false},
+		{"empty rect", vector.New(0, 0), CollisionRect{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PointInRect(test.point, test.rect); got != test.want {
+				t.Errorf("PointInRect(%v, %+v) = %v, want %v", test.point, test.rect, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	triangle := polygon(0, 0, 100, 0, 0, 100)
+
+	tests := []struct {
+		name    string
+		point   vector.Vector2
+		polygon CollisionPolygon
+		want    bool
+	}{
+		{"inside", vector.New(10, 10), triangle, true},
+		{"outside", vector.New(60, 60), triangle, false},
+		{"outside, left of every edge", vector.New(-10, 10), triangle, false},
+		{"counterclockwise", vector.New(10, 10), polygon(0, 0, 0, 100, 100, 0), true},
+		{"concave arm", vector.New(15, 80), testConcave, true},
+		{"concave notch", vector.New(50, 80), testConcave, false},
+		{"concave, ray through both arms", vector.New(-10, 80), testConcave, false},
+		{"empty", vector.New(0, 0), CollisionPolygon{}, false},
+		{"single vertex", vector.New(0, 0), polygon(0, 0), false},
+		{"segment", vector.New(5, 5), polygon(0, 0, 10, 10), false},
+		{"zero area", vector.New(5, 0), polygon(0, 0, 10, 0, 20, 0), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PointInPolygon(test.point, test.polygon); got != test.want {
+				t.Errorf("PointInPolygon(%v, %v) = %v, want %v", test.point, test.polygon.Vertices, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasCollidedCirclePolygon(t *testing.T) {
+	square := polygon(0, 0, 100, 0, 100, 100, 0, 100)
+
+	tests := []struct {
+		name    string
+		circle  CollisionCircle
+		polygon CollisionPolygon
+		want    bool
+	}{
+		{"edge crossing", circle(50, -5, 10), square, true},
+		{"corner inside", circle(103, -4, 6), square, true},
+		{"near the corner, inside the circle's bounds", circle(105, -4, 5), square, false},
+		{"polygon inside circle", circle(0, 0, 100), polygon(20, 30, 30, 30, 30, 40, 20, 40), true},
+		{"circle inside polygon", circle(50, 50, 10), square, true},
+		{"inside concave arm", circle(15, 80, 5), testConcave, true},
+		{"in concave notch", circle(50, 80, 10), testConcave, false},
+		{"touching edge", circle(50, -10, 10), square, true},
+		{"touching corner", circle(103, -4, 5), square, true},
+		{"disjoint", circle(200, 200, 10), square, false},
+		{"empty polygon", circle(0, 0, 10), CollisionPolygon{}, false},
+		{"single vertex inside", circle(0, 0, 10), polygon(5, 5), true},
+		{"segment crossing", circle(0, 0, 10), polygon(-20, 5, 20, 5), true},
+		{"segment outside", circle(0, 0, 10), polygon(-20, 15, 20, 15), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasCollidedCirclePolygon(test.circle, test.polygon); got != test.want {
+				t.Errorf("HasCollidedCirclePolygon(%+v, %v) = %v, want %v", test.circle, test.polygon.Vertices, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasCollidedCircleRect(t *testing.T) {
+	tests := []struct {
+		name   string
+		circle CollisionCircle
+		rect   CollisionRect
+		want   bool
+	}{
+		{"edge crossing", circle(50, -5, 10), testRect, true},
+		{"corner inside", circle(103, -4, 6), testRect, true},
+		{"near the corner, inside the circle's bounds", circle(105, -4, 5), testRect, false},
+		{"rect inside circle", circle(50, 25, 100), testRect, true},
+		{"circle inside rect", circle(50, 25, 10), testRect, true},
+		{"touching edge", circle(50, 60, 10), testRect, true},
+		{"touching corner", circle(103, -4, 5), testRect, true},
+		{"disjoint", circle(50, 61, 10), testRect, false},
+		{"rotated rect crossing", circle(60, 0, 6), testRotatedRect, true},
+		{"beside rotated rect", circle(62, 0, 6), testRotatedRect, false},
+		{"outside rotated rect, inside unrotated", circle(10, 5, 5), testRotatedRect, false},
+		{"inside rotated rect", circle(50, -40, 3), testRotatedRect, true},
+		{"empty rect", circle(0, 0, 10), CollisionRect{Pos: vector.New(5, 5)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasCollidedCircleRect(test.circle, test.rect); got != test.want {
+				t.Errorf("HasCollidedCircleRect(%+v, %+v) = %v, want %v", test.circle, test.rect, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package collision
+
+import (
+	"math"
+	"slices"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+// AABB is an axis aligned bounding box
+type AABB struct {
+	Min, Max vector.Vector2
+}
+
+func (box AABB) Overlaps(other AABB) bool {
+	return box.Min.X <= other.Max.X && other.Min.X <= box.Max.X &&
+		box.Min.Y <= other.Max.Y && other.Min.Y <= box.Max.Y
+}
+
+func (box AABB) Union(other AABB) AABB {
+	return AABB{
+		Min: vector.New(min(box.Min.X, other.Min.X), min(box.Min.Y, other.Min.Y)),
+		Max: vector.New(max(box.Max.X, other.Max.X), max(box.Max.Y, other.Max.Y)),
+	}
+}
+
+func boundsOf(vertices []vector.Vector2) AABB {
+	box := AABB{
+		Min: vector.New(math.Inf(1), math.Inf(1)),
+		Max: vector.New(math.Inf(-1), math.Inf(-1)),
+	}
+	for _, vertex := range vertices {
+		box.Min = vector.New(min(box.Min.X, vertex.X), min(box.Min.Y, vertex.Y))
+		box.Max = vector.New(max(box.Max.X, vertex.X), max(box.Max.Y, vertex.Y))
+	}
+	return box
+}
+
+func (rect CollisionRect) Bounds() AABB {
+	if rect.Rotation != 0 {
+		return boundsOf(rect.Vertices())
+	}
+	return AABB{Min: rect.Pos, Max: vector.New(rect.Pos.X+rect.W, rect.Pos.Y+rect.H)}
+}
+
+func (polygon CollisionPolygon) Bounds() AABB {
+	return boundsOf(polygon.Vertices)
+}
+
+func (circle CollisionCircle) Bounds() AABB {
+	return AABB{
+		Min: circle.Center.SubScalar(circle.Radius),
+		Max: circle.Center.AddScalar(circle.Radius),
+	}
+}
+
+type cell struct {
+	x, y int
+}
+
+// cellRange is the inclusive range of cells a box covers
+type cellRange struct {
+	min, max cell
+}
+
+/*
+Grid is a uniform grid broad-phase: every collider registers its bounding box
+and is listed in each cell the box covers, so a query only looks at the cells
+around the area it asks about instead of at every collider of the level
+*/
+type Grid struct {
+	cellSize float64
+	cells    map[cell][]int
+	ranges   []cellRange
+	bounds   []AABB
+	// stamps dedupes colliders covering several of the queried cells
+	stamps []uint32
+	query  uint32
+}
+
+func NewGrid(cellSize float64) *Grid {
+	return &Grid{
+		cellSize: cellSize,
+		cells:    map[cell][]int{},
+	}
+}
+
+func (grid *Grid) cellRange(box AABB) cellRange {
+	return cellRange{
+		min: cell{int(math.Floor(box.Min.X / grid.cellSize)), int(math.Floor(box.Min.Y / grid.cellSize))},
+		max: cell{int(math.Floor(box.Max.X / grid.cellSize)), int(math.Floor(box.Max.Y / grid.cellSize))},
+	}
+}
+
+func (grid *Grid) forEachCell(cells cellRange, do func(c cell)) {
+	for x := cells.min.x; x <= cells.max.x; x++ {
+		for y := cells.min.y; y <= cells.max.y; y++ {
+			do(cell{x, y})
+		}
+	}
+}
+
+// Insert registers a collider and returns its id, ids are given in order starting at 0
+func (grid *Grid) Insert(box AABB) int {
+	id := len(grid.bounds)
+	cells := grid.cellRange(box)
+
+	grid.bounds = append(grid.bounds, box)
+	grid.ranges = append(grid.ranges, cells)
+	grid.stamps = append(grid.stamps, 0)
+	grid.forEachCell(cells, func(c cell) {
+		grid.cells[c] = append(grid.cells[c], id)
+	})
+	return id
+}
+
+// Update moves a collider, it only touches the cells when the box moved to other ones
+func (grid *Grid) Update(id int, box AABB) {
+	grid.bounds[id] = box
+
+	cells := grid.cellRange(box)
+	if cells == grid.ranges[id] {
+		return
+	}
+
+	grid.forEachCell(grid.ranges[id], func(c cell) {
+		ids := grid.cells[c]
+		if i := slices.Index(ids, id); i >= 0 {
+			grid.cells[c] = slices.Delete(ids, i, i+1)
+		}
+	})
+	grid.ranges[id] = cells
+	grid.forEachCell(cells, func(c cell) {
+		grid.cells[c] = append(grid.cells[c], id)
+	})
+}
+
+// Query appends to dst the id of every collider whose bounding box overlaps box, each once and in no particular order
+func (grid *Grid) Query(box AABB, dst []int) []int {
+	if box.Min.X > box.Max.X || box.Min.Y > box.Max.Y {
+		return dst
+	}
+
+	grid.query++
+	grid.forEachCell(grid.cellRange(box), func(c cell) {
+		for _, id := range grid.cells[c] {
+			if grid.stamps[id] != grid.query && grid.bounds[id].Overlaps(box) {
+				grid.stamps[id] = grid.query
+				dst = append(dst, id)
+			}
+		}
+	})
+	return dst
+}
+
+func (grid *Grid) Clear() {
+	clear(grid.cells)
+	grid.ranges = grid.ranges[:0]
+	grid.bounds = grid.bounds[:0]
+	grid.stamps = grid.stamps[:0]
+}
@@ -0,0 +1,134 @@
+package collision
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+func box(minX, minY, maxX, maxY float64) AABB {
+	return AABB{Min: vector.New(minX, minY), Max: vector.New(maxX, maxY)}
+}
+
+// query returns the ids overlapping area sorted, as Query gives them in no particular order
+func query(grid *Grid, area AABB) []int {
+	ids := grid.Query(area, nil)
+	slices.Sort(ids)
+	return ids
+}
+
+func TestGridQuery(t *testing.T) {
+	grid := NewGrid(100)
+	colliders := []AABB{
+		box(10, 10, 20, 20),
+		// covers four cells
+		box(90, 90, 110, 110),
+		box(-150, -150, -130, -130),
+		// straddles the origin
+		box(-10, -10, 10, 10),
+		box(500, 500, 900, 520),
+	}
+	for i, collider := range colliders {
+		if id := grid.Insert(collider); id != i {
+			t.Fatalf("collider %d got id %d", i, id)
+		}
+	}
+
+	tests := []struct {
+		name string
+		area AABB
+		want []int
+	}{
+		{"one cell", box(5, 5, 15, 15), []int{0, 3}},
+		{"same cell, no overlap", box(30, 30, 40, 40), nil},
+		{"every cell of a collider", box(0, 0, 200, 200), []int{0, 1, 3}},
+		{"corner of a multi cell collider", box(105, 105, 120, 120), []int{1}},
+		{"negative coordinates", box(-140, -140, -135, -135), []int{2}},
+		{"negative cell, no overlap", box(-190, -190, -160, -160), nil},
+		{"across the origin", box(-5, -5, -1, -1), []int{3}},
+		{"touching", box(20, 20, 30, 30), []int{0}},
+		{"long collider, far cell", box(850, 510, 860, 515), []int{4}},
+		{"everything", box(-1000, -1000, 1000, 1000), []int{0, 1, 2, 3, 4}},
+		{"empty area", box(1000, 1000, 2000, 2000), nil},
+		{"inverted area", box(20, 20, 0, 0), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := query(grid, test.area); !slices.Equal(got, test.want) {
+				t.Errorf("Query(%v) = %v, want %v", test.area, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGridQueryDedupes(t *testing.T) {
+	grid := NewGrid(10)
+	grid.Insert(box(0, 0, 95, 95))
+
+	// the collider is in all 100 cells the query looks at, twice in a row
+	for i := 0; i < 2; i++ {
+		if got := grid.Query(box(0, 0, 95, 95), nil); !slices.Equal(got, []int{0}) {
+			t.Fatalf("query %d got %v", i, got)
+		}
+	}
+
+	dst := grid.Query(box(0, 0, 1, 1), []int{42})
+	if !slices.Equal(dst, []int{42, 0}) {
+		t.Errorf("Query should append to dst, got %v", dst)
+	}
+}
+
+func TestGridUpdate(t *testing.T) {
+	grid := NewGrid(100)
+	moving := grid.Insert(box(10, 10, 20, 20))
+	still := grid.Insert(box(30, 30, 40, 40))
+
+	// within its cell
+	grid.Update(moving, box(50, 50, 60, 60))
+	if got := query(grid, box(10, 10, 20, 20)); len(got) != 0 {
+		t.Errorf("old bounds still found %v", got)
+	}
+	if got := query(grid, box(55, 55, 56, 56)); !slices.Equal(got, []int{moving}) {
+		t.Errorf("new bounds found %v", got)
+	}
+
+	// across cells, into negative ones
+	grid.Update(moving, box(-250, 150, -180, 260))
+	if got := query(grid, box(0, 0, 99, 99)); !slices.Equal(got, []int{still}) {
+		t.Errorf("old cell found %v", got)
+	}
+	for _, area := range []AABB{box(-250, 150, -240, 160), box(-190, 250, -180, 260)} {
+		if got := query(grid, area); !slices.Equal(got, []int{moving}) {
+			t.Errorf("Query(%v) = %v after moving across cells", area, got)
+		}
+	}
+
+	// and back, leaving no trace in the cells it left
+	grid.Update(moving, box(10, 10, 20, 20))
+	if got := query(grid, box(-1000, 100, -1, 1000)); len(got) != 0 {
+		t.Errorf("cells left behind still found %v", got)
+	}
+	if got := query(grid, box(0, 0, 99, 99)); !slices.Equal(got, []int{moving, still}) {
+		t.Errorf("moving back found %v", got)
+	}
+}
+
+func TestGridClear(t *testing.T) {
+	grid := NewGrid(100)
+	grid.Insert(box(10, 10, 20, 20))
+	grid.Insert(box(-300, -300, 300, 300))
+	grid.Clear()
+
+	if got := query(grid, box(-1000, -1000, 1000, 1000)); len(got) != 0 {
+		t.Fatalf("cleared grid found %v", got)
+	}
+
+	if id := grid.Insert(box(500, 500, 510, 510)); id != 0 {
+		t.Errorf("ids should start over at 0, got %d", id)
+	}
+	if got := query(grid, box(-1000, -1000, 1000, 1000)); !slices.Equal(got, []int{0}) {
+		t.Errorf("got %v after inserting again", got)
+	}
+}
@@ -0,0 +1,284 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+/*
+Manifold describes how two shapes a and b overlap. Normal has unit length and
+points from a towards b, so moving a by -Normal*Depth (or b by Normal*Depth)
+separates them, and it can be fed to vector.Reflect to bounce off the surface
+*/
+type Manifold struct {
+	// Point is where the shapes touch: the point of one shape deepest inside the other
+	Point  vector.Vector2
+	Normal vector.Vector2
+	Depth  float64
+}
+
+func centroid(vertices []vector.Vector2) vector.Vector2 {
+	sum := vector.New(0, 0)
+	for _, vertex := range vertices {
+		sum.Add(vertex)
+	}
+	return sum.DivScalar(float64(len(vertices)))
+}
+
+// support is the vertex of polygon furthest along direction
+func support(polygon CollisionPolygon, direction vector.Vector2) vector.Vector2 {
+	best, bestProj := polygon.Vertices[0], math.Inf(-1)
+	for _, vertex := range polygon.Vertices {
+		if proj := vertex.Dot(&direction); proj > bestProj {
+			best, bestProj = vertex, proj
+		}
+	}
+	return best
+}
+
+// containsEpsilon keeps vertices lying on an edge inside despite rounding
+const containsEpsilon = 1e-9
+
+// containsPoint is true when point is inside the convex polygon or on its boundary, whatever its winding
+func containsPoint(polygon CollisionPolygon, point vector.Vector2) bool {
+	if len(polygon.Vertices) < 3 {
+		return false
+	}
+
+	clockwise, counterclockwise := false, false
+	for current := range polygon.Vertices {
+		next := (current + 1) % len(polygon.Vertices)
+		turn := cross(polygon.Vertices[current], polygon.Vertices[next], point)
+		clockwise = clockwise || turn > containsEpsilon
+		counterclockwise = counterclockwise || turn < -containsEpsilon
+	}
+	return !(clockwise && counterclockwise)
+}
+
+/*
+contactPoint is the vertex of either polygon that went deepest inside the
+other, normal pointing from a towards b. When no vertex is inside the other
+polygon, edges crossing edges, it is the vertex of the polygon whose face was
+not hit furthest across that face
+*/
+func contactPoint(a, b CollisionPolygon, normal vector.Vector2, fromA bool) vector.Vector2 {
+	var (
+		point   vector.Vector2
+		deepest = math.Inf(-1)
+	)
+
+	_, maxA := project(a, normal)
+	for _, vertex := range b.Vertices {
+		if depth := maxA - vertex.Dot(&normal); depth > deepest && containsPoint(a, vertex) {
+			point, deepest = vertex, depth
+		}
+	}
+	minB, _ := project(b, normal)
+	for _, vertex := range a.Vertices {
+		if depth := vertex.Dot(&normal) - minB; depth > deepest && containsPoint(b, vertex) {
+			point, deepest = vertex, depth
+		}
+	}
+	if !math.IsInf(deepest, -1) {
+		return point
+	}
+
+	if fromA {
+		return support(b, normal.MulScalar(-1))
+	}
+	return support(a, normal)
+}
+
+// edgeNormals are the unit normals of polygon's edges, a two vertex polygon being a segment
+func edgeNormals(polygon CollisionPolygon) []vector.Vector2 {
+	normals := make([]vector.Vector2, 0, len(polygon.Vertices))
+	for current := range polygon.Vertices {
+		next := (current + 1) % len(polygon.Vertices)
+		edge := polygon.Vertices[next].SubOut(polygon.Vertices[current])
+		if edge.X == 0 && edge.Y == 0 {
+			continue
+		}
+		normal := vector.New(-edge.Y, edge.X)
+		normals = append(normals, normal.Normalize())
+	}
+	return normals
+}
+
+/*
+manifoldConvexPolygons is the separating axis test keeping the axis of least
+overlap, which is the contact normal and its overlap the depth
+*/
+func manifoldConvexPolygons(a, b CollisionPolygon) (Manifold, bool) {
+	if len(a.Vertices) == 0 || len(b.Vertices) == 0 {
+		return Manifold{}, false
+	}
+
+	manifold := Manifold{Depth: math.Inf(1)}
+	fromA := false
+	for i, polygon := range []CollisionPolygon{a, b} {
+		for _, axis := range edgeNormals(polygon) {
+			minA, maxA := project(a, axis)
+			minB, maxB := project(b, axis)
+			overlap := min(maxA-minB, maxB-minA)
+			if overlap < 0 {
+				return Manifold{}, false
+			}
+			if overlap < manifold.Depth {
+				manifold.Depth = overlap
+				manifold.Normal = axis
+				fromA = i == 0
+			}
+		}
+	}
+
+	centerA, centerB := centroid(a.Vertices), centroid(b.Vertices)
+	direction := centerB.SubOut(centerA)
+	if direction.Dot(&manifold.Normal) < 0 {
+		manifold.Normal = manifold.Normal.MulScalar(-1)
+	}
+
+	manifold.Point = contactPoint(a, b, manifold.Normal, fromA)
+	return manifold, true
+}
+
+// ManifoldPolygons works on any simple polygon, keeping the deepest overlap of their convex parts
+func ManifoldPolygons(a, b CollisionPolygon) (Manifold, bool) {
+	var (
+		deepest Manifold
+		found   bool
+	)
+	for _, partA := range Decompose(a) {
+		for _, partB := range Decompose(b) {
+			manifold, collided := manifoldConvexPolygons(partA, partB)
+			if collided && (!found || manifold.Depth > deepest.Depth) {
+				deepest, found = manifold, true
+			}
+		}
+	}
+	return deepest, found
+}
+
+func ManifoldRectPolygon(rect CollisionRect, polygon CollisionPolygon) (Manifold, bool) {
+	return ManifoldPolygons(rect.Polygon(), polygon)
+}
+
+func ManifoldRects(a, b CollisionRect) (Manifold, bool) {
+	return manifoldConvexPolygons(a.Polygon(), b.Polygon())
+}
+
+func ManifoldCircles(a, b CollisionCircle) (Manifold, bool) {
+	direction := b.Center.SubOut(a.Center)
+	distance := direction.Magnitude()
+	depth := a.Radius + b.Radius - distance
+	if depth < 0 {
+		return Manifold{}, false
+	}
+
+	// concentric circles have no preferred direction, push them apart along any axis
+	normal := vector.New(1, 0)
+	if distance > 0 {
+		normal = direction.DivScalar(distance)
+	}
+
+	// a's point furthest towards b, unless b sits inside a and that point is past b
+	point := a.Center.AddOut(normal.MulScalar(a.Radius))
+	if !inCircle(point, b) {
+		point = b.Center.SubOut(normal.MulScalar(b.Radius))
+	}
+	return Manifold{
+		Point:  point,
+		Normal: normal,
+		Depth:  depth,
+	}, true
+}
+
+// inCircle is PointInCircle letting points on the circle through despite rounding
+func inCircle(point vector.Vector2, circle CollisionCircle) bool {
+	return point.Distance(circle.Center) <= circle.Radius+containsEpsilon
+}
+
+/*
+manifoldCircleConvexPolygon tests the polygon's edge normals and the axis from
+the polygon's closest vertex to the circle's center, which covers the circle
+hitting a corner
+*/
+func manifoldCircleConvexPolygon(circle CollisionCircle, polygon CollisionPolygon) (Manifold, bool) {
+	if len(polygon.Vertices) == 0 {
+		return Manifold{}, false
+	}
+
+	closest := polygon.Vertices[0]
+	for _, vertex := range polygon.Vertices {
+		if vertex.Distance(circle.Center) < closest.Distance(circle.Center) {
+			closest = vertex
+		}
+	}
+
+	axes := edgeNormals(polygon)
+	if toCenter := circle.Center.SubOut(closest); toCenter.X != 0 || toCenter.Y != 0 {
+		axes = append(axes, toCenter.Normalize())
+	}
+
+	manifold := Manifold{Depth: math.Inf(1)}
+	for _, axis := range axes {
+		center := circle.Center.Dot(&axis)
+		minCircle, maxCircle := center-circle.Radius, center+circle.Radius
+		minPolygon, maxPolygon := project(polygon, axis)
+		overlap := min(maxCircle-minPolygon, maxPolygon-minCircle)
+		if overlap < 0 {
+			return Manifold{}, false
+		}
+		if overlap < manifold.Depth {
+			manifold.Depth = overlap
+			manifold.Normal = axis
+		}
+	}
+
+	center := centroid(polygon.Vertices)
+	direction := center.SubOut(circle.Center)
+	if direction.Dot(&manifold.Normal) < 0 {
+		manifold.Normal = manifold.Normal.MulScalar(-1)
+	}
+	manifold.Point = circleContactPoint(circle, polygon, manifold.Normal)
+	return manifold, true
+}
+
+/*
+circleContactPoint is the circle's point furthest along normal when it is
+inside the polygon. Otherwise, the polygon sitting inside the circle or poking
+into it with a corner, it is the polygon's vertex closest to the center
+*/
+func circleContactPoint(circle CollisionCircle, polygon CollisionPolygon, normal vector.Vector2) vector.Vector2 {
+	point := circle.Center.AddOut(normal.MulScalar(circle.Radius))
+	if containsPoint(polygon, point) {
+		return point
+	}
+
+	closest := math.Inf(1)
+	for _, vertex := range polygon.Vertices {
+		if distance := vertex.Distance(circle.Center); distance < closest && inCircle(vertex, circle) {
+			point, closest = vertex, distance
+		}
+	}
+	return point
+}
+
+// ManifoldCirclePolygon works on any simple polygon, the normal points from the circle towards the polygon
+func ManifoldCirclePolygon(circle CollisionCircle, polygon CollisionPolygon) (Manifold, bool) {
+	var (
+		deepest Manifold
+		found   bool
+	)
+	for _, part := range Decompose(polygon) {
+		manifold, collided := manifoldCircleConvexPolygon(circle, part)
+		if collided && (!found || manifold.Depth > deepest.Depth) {
+			deepest, found = manifold, true
+		}
+	}
+	return deepest, found
+}
+
+func ManifoldCircleRect(circle CollisionCircle, rect CollisionRect) (Manifold, bool) {
+	return manifoldCircleConvexPolygon(circle, rect.Polygon())
+}
@@ -0,0 +1,298 @@
+package collision
+
+import (
+	"math"
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+const epsilon = 1e-9
+
+func near(a, b vector.Vector2) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+type manifoldTest struct {
+	name     string
+	manifold func() (Manifold, bool)
+	want     Manifold
+	collided bool
+}
+
+func runManifoldTests(t *testing.T, tests []manifoldTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, collided := test.manifold()
+			if collided != test.collided {
+				t.Fatalf("collided is %v, want %v", collided, test.collided)
+			}
+			if !collided {
+				return
+			}
+			if !near(got.Normal, test.want.Normal) || math.Abs(got.Depth-test.want.Depth) > epsilon || !near(got.Point, test.want.Point) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+var (
+	// a 20x20 square turned 45 degrees around (110, 25), its left corner pokes into testRect
+	diamond = CollisionRect{Pos: vector.New(100, 15), W: 20, H: 20, Rotation: math.Pi / 4}
+	// an arrow pointing up into the bottom of a 100x100 square
+	arrow  = polygon(50, 90, 80, 130, 20, 130)
+	box100 = polygon(0, 0, 100, 0, 100, 100, 0, 100)
+)
+
+func TestManifoldRects(t *testing.T) {
+	overlapping := CollisionRect{Pos: vector.New(90, 10), W: 40, H: 20}
+
+	runManifoldTests(t, []manifoldTest{
+		{
+			name:     "overlapping",
+			manifold: func() (Manifold, bool) { return ManifoldRects(testRect, overlapping) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 10, Point: vector.New(90, 10)},
+			collided: true,
+		},
+		{
+			name:     "normal points from a towards b",
+			manifold: func() (Manifold, bool) { return ManifoldRects(overlapping, testRect) },
+			want:     Manifold{Normal: vector.New(-1, 0), Depth: 10, Point: vector.New(90, 10)},
+			collided: true,
+		},
+		{
+			name:     "rotated corner",
+			manifold: func() (Manifold, bool) { return ManifoldRects(testRect, diamond) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 10*math.Sqrt2 - 10, Point: vector.New(110-10*math.Sqrt2, 25)},
+			collided: true,
+		},
+		{
+			name: "touching",
+			manifold: func() (Manifold, bool) {
+				return ManifoldRects(testRect, CollisionRect{Pos: vector.New(100, 0), W: 10, H: 10})
+			},
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 0, Point: vector.New(100, 0)},
+			collided: true,
+		},
+		{
+			name: "disjoint",
+			manifold: func() (Manifold, bool) {
+				return ManifoldRects(testRect, CollisionRect{Pos: vector.New(101, 0), W: 10, H: 10})
+			},
+		},
+	})
+}
+
+func TestManifoldPolygons(t *testing.T) {
+	runManifoldTests(t, []manifoldTest{
+		{
+			name:     "vertex into face",
+			manifold: func() (Manifold, bool) { return ManifoldPolygons(box100, arrow) },
+			want:     Manifold{Normal: vector.New(0, 1), Depth: 10, Point: vector.New(50, 90)},
+			collided: true,
+		},
+		{
+			name:     "normal points from a towards b",
+			manifold: func() (Manifold, bool) { return ManifoldPolygons(arrow, box100) },
+			want:     Manifold{Normal: vector.New(0, -1), Depth: 10, Point: vector.New(50, 90)},
+			collided: true,
+		},
+		{
+			name:     "rect and polygon",
+			manifold: func() (Manifold, bool) { return ManifoldRectPolygon(testRect, diamond.Polygon()) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 10*math.Sqrt2 - 10, Point: vector.New(110-10*math.Sqrt2, 25)},
+			collided: true,
+		},
+		{
+			name: "concave arm",
+			manifold: func() (Manifold, bool) {
+				return ManifoldPolygons(testConcave, polygon(10, 90, 20, 90, 20, 110, 10, 110))
+			},
+			want:     Manifold{Normal: vector.New(0, 1), Depth: 10, Point: vector.New(10, 90)},
+			collided: true,
+		},
+		{
+			// the face hit belongs to the square, the contact still is the square's corner inside the U
+			name: "concave arm, normal points from a towards b",
+			manifold: func() (Manifold, bool) {
+				return ManifoldPolygons(polygon(10, 90, 20, 90, 20, 110, 10, 110), testConcave)
+			},
+			want:     Manifold{Normal: vector.New(0, -1), Depth: 10, Point: vector.New(10, 90)},
+			collided: true,
+		},
+		{
+			name:     "concave notch ceiling",
+			manifold: func() (Manifold, bool) { return ManifoldPolygons(testConcave, polygon(50, 25, 60, 45, 40, 45)) },
+			want:     Manifold{Normal: vector.New(0, 1), Depth: 5, Point: vector.New(50, 25)},
+			collided: true,
+		},
+		{
+			name:     "inside the concave notch",
+			manifold: func() (Manifold, bool) { return ManifoldPolygons(testConcave, polygon(40, 50, 60, 50, 60, 70, 40, 70)) },
+		},
+		{
+			name:     "empty polygon",
+			manifold: func() (Manifold, bool) { return ManifoldPolygons(box100, CollisionPolygon{}) },
+		},
+	})
+}
+
+func TestManifoldCircles(t *testing.T) {
+	runManifoldTests(t, []manifoldTest{
+		{
+			name:     "overlapping",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(0, 0, 10), circle(15, 0, 10)) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 5, Point: vector.New(10, 0)},
+			collided: true,
+		},
+		{
+			name:     "diagonal",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(0, 0, 10), circle(6, 8, 5)) },
+			want:     Manifold{Normal: vector.New(.6, .8), Depth: 5, Point: vector.New(6, 8)},
+			collided: true,
+		},
+		{
+			name:     "normal points from a towards b",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(6, 8, 5), circle(0, 0, 10)) },
+			want:     Manifold{Normal: vector.New(-.6, -.8), Depth: 5, Point: vector.New(3, 4)},
+			collided: true,
+		},
+		{
+			name:     "concentric",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(5, 5, 10), circle(5, 5, 4)) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 14, Point: vector.New(1, 5)},
+			collided: true,
+		},
+		{
+			name:     "b inside a",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(0, 0, 100), circle(50, 0, 10)) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 60, Point: vector.New(40, 0)},
+			collided: true,
+		},
+		{
+			name:     "a inside b",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(50, 0, 10), circle(0, 0, 100)) },
+			want:     Manifold{Normal: vector.New(-1, 0), Depth: 60, Point: vector.New(40, 0)},
+			collided: true,
+		},
+		{
+			name:     "touching",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(0, 0, 10), circle(20, 0, 10)) },
+			want:     Manifold{Normal: vector.New(1, 0), Depth: 0, Point: vector.New(10, 0)},
+			collided: true,
+		},
+		{
+			name:     "disjoint",
+			manifold: func() (Manifold, bool) { return ManifoldCircles(circle(0, 0, 10), circle(21, 0, 10)) },
+		},
+	})
+}
+
+func TestManifoldCircleRect(t *testing.T) {
+	diamondAtOrigin := CollisionRect{Pos: vector.New(0, 0), W: 20, H: 20, Rotation: math.Pi / 4}
+	// the normal of a square inside a circle runs from the center through the square's closest corner (20, 30)
+	towardsSquare := vector.New(2/math.Sqrt(13), 3/math.Sqrt(13))
+
+	runManifoldTests(t, []manifoldTest{
+		{
+			name:     "face",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(50, -5, 10), testRect) },
+			want:     Manifold{Normal: vector.New(0, 1), Depth: 5, Point: vector.New(50, 5)},
+			collided: true,
+		},
+		{
+			name:     "corner",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(103, -4, 6), testRect) },
+			want:     Manifold{Normal: vector.New(-.6, .8), Depth: 1, Point: vector.New(99.4, .8)},
+			collided: true,
+		},
+		{
+			name:     "center inside",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(50, 40, 5), testRect) },
+			want:     Manifold{Normal: vector.New(0, -1), Depth: 15, Point: vector.New(50, 35)},
+			collided: true,
+		},
+		{
+			name:     "rotated rect face",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(62, 0, 10), testRotatedRect) },
+			want:     Manifold{Normal: vector.New(-1, 0), Depth: 3, Point: vector.New(52, 0)},
+			collided: true,
+		},
+		{
+			name:     "rotated rect corner",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(30, 10, 7), diamondAtOrigin) },
+			want:     Manifold{Normal: vector.New(-1, 0), Depth: 10 + 10*math.Sqrt2 - 23, Point: vector.New(23, 10)},
+			collided: true,
+		},
+		{
+			name:     "disjoint",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(50, -20, 10), testRect) },
+		},
+		{
+			name:     "disjoint, near the corner",
+			manifold: func() (Manifold, bool) { return ManifoldCircleRect(circle(105, -3, 5), testRect) },
+		},
+		{
+			name: "rect inside circle",
+			manifold: func() (Manifold, bool) {
+				return ManifoldCircleRect(circle(0, 0, 100), CollisionRect{Pos: vector.New(20, 30), W: 10, H: 10})
+			},
+			want:     Manifold{Normal: towardsSquare, Depth: 100 - 10*math.Sqrt(13), Point: vector.New(20, 30)},
+			collided: true,
+		},
+		{
+			name: "polygon inside circle",
+			manifold: func() (Manifold, bool) {
+				return ManifoldCirclePolygon(circle(0, 0, 100), polygon(20, 30, 30, 30, 30, 40, 20, 40))
+			},
+			want:     Manifold{Normal: towardsSquare, Depth: 100 - 10*math.Sqrt(13), Point: vector.New(20, 30)},
+			collided: true,
+		},
+		{
+			name:     "circle inside polygon",
+			manifold: func() (Manifold, bool) { return ManifoldCirclePolygon(circle(50, 30, 10), box100) },
+			want:     Manifold{Normal: vector.New(0, 1), Depth: 40, Point: vector.New(50, 40)},
+			collided: true,
+		},
+		{
+			name:     "circle and concave polygon",
+			manifold: func() (Manifold, bool) { return ManifoldCirclePolygon(circle(15, 105, 10), testConcave) },
+			want:     Manifold{Normal: vector.New(0, -1), Depth: 5, Point: vector.New(15, 95)},
+			collided: true,
+		},
+	})
+}
+
+// TestManifoldSeparates checks the normal's sign: moving b by Normal*Depth leaves the shapes touching at most
+func TestManifoldSeparates(t *testing.T) {
+	pairs := []struct {
+		name string
+		a, b CollisionPolygon
+	}{
+		{"rects", testRect.Polygon(), CollisionRect{Pos: vector.New(90, 10), W: 40, H: 20}.Polygon()},
+		{"rotated rect", testRect.Polygon(), diamond.Polygon()},
+		{"polygons", box100, arrow},
+		{"polygons swapped", arrow, box100},
+		{"concave", testConcave, polygon(10, 90, 20, 90, 20, 110, 10, 110)},
+		{"concave swapped", polygon(10, 90, 20, 90, 20, 110, 10, 110), testConcave},
+	}
+
+	for _, pair := range pairs {
+		t.Run(pair.name, func(t *testing.T) {
+			manifold, collided := ManifoldPolygons(pair.a, pair.b)
+			if !collided {
+				t.Fatal("expected a collision")
+			}
+
+			moved := CollisionPolygon{Vertices: make([]vector.Vector2, len(pair.b.Vertices))}
+			for i, vertex := range pair.b.Vertices {
+				moved.Vertices[i] = vertex.AddOut(manifold.Normal.MulScalar(manifold.Depth + 1e-6))
+			}
+			if again, collided := ManifoldPolygons(pair.a, moved); collided {
+				t.Errorf("still colliding after moving b along %v by %v: %+v", manifold.Normal, manifold.Depth, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package collision
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+// signedArea is positive when the vertices go clockwise on screen (y pointing down)
+func signedArea(vertices []vector.Vector2) float64 {
+	area := 0.0
+	for current := range vertices {
+		next := (current + 1) % len(vertices)
+		area += vertices[current].X*vertices[next].Y - vertices[next].X*vertices[current].Y
+	}
+	return area / 2
+}
+
+// cross is the z of (b - a) x (c - a), positive when a, b, c turn clockwise on screen
+func cross(a, b, c vector.Vector2) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+func isConvex(vertices []vector.Vector2) bool {
+	sign := 0.0
+	for current := range vertices {
+		turn := cross(vertices[current], vertices[(current+1)%len(vertices)], vertices[(current+2)%len(vertices)])
+		if turn == 0 {
+			continue
+		}
+		if sign != 0 && math.Signbit(turn) != math.Signbit(sign) {
+			return false
+		}
+		sign = turn
+	}
+	return true
+}
+
+func isInTriangle(p, a, b, c vector.Vector2) bool {
+	return cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0
+}
+
+/*
+Triangulate splits a simple polygon, convex or concave, in triangles by ear
+clipping. It returns indices into vertices, whatever their winding. Polygons
+ValidatePolygon rejects are only partially triangulated
+Source: https://www.geometrictools.com/Documentation/TriangulationByEarClipping.pdf
+*/
+func Triangulate(vertices []vector.Vector2) [][3]int {
+	triangles, _ := triangulate(vertices)
+	return triangles
+}
+
+// triangulate also reports whether every vertex ended up in a triangle
+func triangulate(vertices []vector.Vector2) ([][3]int, bool) {
+	if len(vertices) < 3 {
+		return nil, false
+	}
+
+	// work clockwise so every ear turns the same way
+	remaining := make([]int, len(vertices))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	if signedArea(vertices) < 0 {
+		for i, j := 0, len(remaining)-1; i < j; i, j = i+1, j-1 {
+			remaining[i], remaining[j] = remaining[j], remaining[i]
+		}
+	}
+
+	triangles := make([][3]int, 0, len(vertices)-2)
+	for len(remaining) > 3 {
+		earFound := false
+		for i := range remaining {
+			prev := remaining[(i+len(remaining)-1)%len(remaining)]
+			current := remaining[i]
+			next := remaining[(i+1)%len(remaining)]
+			a, b, c := vertices[prev], vertices[current], vertices[next]
+
+			if cross(a, b, c) <= 0 {
+				continue
+			}
+
+			isEar := true
+			for _, other := range remaining {
+				if other != prev && other != current && other != next && isInTriangle(vertices[other], a, b, c) {
+					isEar = false
+					break
+				}
+			}
+			if !isEar {
+				continue
+			}
+
+			triangles = append(triangles, [3]int{prev, current, next})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			earFound = true
+			break
+		}
+
+		// only degenerate polygons (self intersecting, collinear) run out of ears
+		if !earFound {
+			break
+		}
+	}
+
+	if len(remaining) != 3 {
+		return triangles, false
+	}
+	return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]}), true
+}
+
+// onSegment reports whether p, known to be collinear with a and b, lies between them
+func onSegment(a, b, p vector.Vector2) bool {
+	return p.X >= min(a.X, b.X) && p.X <= max(a.X, b.X) && p.Y >= min(a.Y, b.Y) && p.Y <= max(a.Y, b.Y)
+}
+
+// segmentsIntersect is true when ab and cd share any point, touching and collinear overlaps included
+func segmentsIntersect(a, b, c, d vector.Vector2) bool {
+	var (
+		d1 = cross(c, d, a)
+		d2 = cross(c, d, b)
+		d3 = cross(a, b, c)
+		d4 = cross(a, b, d)
+	)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(c, d, a)) ||
+		(d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) ||
+		(d4 == 0 && onSegment(a, b, d))
+}
+
+var (
+	ErrTooFewVertices   = errors.New("a polygon needs at least 3 vertices")
+	ErrZeroArea         = errors.New("polygon has no area")
+	ErrSelfIntersecting = errors.New("polygon edges cross each other")
+)
+
+/*
+ValidatePolygon reports why Decompose could not split vertices in convex
+parts: too few vertices, no area, or edges that cross, touch or fold back on
+each other. It is meant for level loading, Decompose does not check
+*/
+func ValidatePolygon(vertices []vector.Vector2) error {
+	if len(vertices) < 3 {
+		return ErrTooFewVertices
+	}
+	if signedArea(vertices) == 0 {
+		return ErrZeroArea
+	}
+
+	n := len(vertices)
+	for i := 0; i < n; i++ {
+		a, b := vertices[i], vertices[(i+1)%n]
+		if a == b {
+			return fmt.Errorf("%w: vertex %d is repeated", ErrSelfIntersecting, i)
+		}
+
+		// the next edge shares b, it only overlaps this one when it goes straight back
+		c := vertices[(i+2)%n]
+		edge, nextEdge := b.SubOut(a), c.SubOut(b)
+		if cross(a, b, c) == 0 && edge.Dot(&nextEdge) < 0 {
+			return fmt.Errorf("%w: edges %d and %d overlap", ErrSelfIntersecting, i, (i+1)%n)
+		}
+
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentsIntersect(a, b, vertices[j], vertices[(j+1)%n]) {
+				return fmt.Errorf("%w: edges %d and %d", ErrSelfIntersecting, i, j)
+			}
+		}
+	}
+
+	if _, complete := triangulate(vertices); !complete {
+		return fmt.Errorf("%w: it cannot be triangulated", ErrSelfIntersecting)
+	}
+	return nil
+}
+
+// convexIndices splits vertices in convex parts given as indices into vertices, a convex polygon being a single part
+func convexIndices(vertices []vector.Vector2) [][]int {
+	if len(vertices) < 4 || isConvex(vertices) {
+		part := make([]int, len(vertices))
+		for i := range part {
+			part[i] = i
+		}
+		return [][]int{part}
+	}
+
+	triangles := Triangulate(vertices)
+	parts := make([][]int, len(triangles))
+	for i, triangle := range triangles {
+		parts[i] = []int{triangle[0], triangle[1], triangle[2]}
+	}
+	return parts
+}
+
+/*
+ConvexParts is the convex decomposition of a polygon kept as vertex indices,
+so a polygon that moves is decomposed once and its parts follow it around.
+Moving, rotating, scaling or mirroring the polygon keeps its parts convex
+*/
+type ConvexParts struct {
+	indices [][]int
+	parts   []CollisionPolygon
+}
+
+func NewConvexParts(vertices []vector.Vector2) *ConvexParts {
+	indices := convexIndices(vertices)
+	parts := make([]CollisionPolygon, len(indices))
+	for i, part := range indices {
+		parts[i].Vertices = make([]vector.Vector2, len(part))
+	}
+	return &ConvexParts{indices: indices, parts: parts}
+}
+
+/*
+At returns the parts of polygon, which must have the vertices the parts were
+made from, transformed. The parts are overwritten by the next call
+*/
+func (convex *ConvexParts) At(polygon CollisionPolygon) []CollisionPolygon {
+	for i, part := range convex.indices {
+		for j, index := range part {
+			convex.parts[i].Vertices[j] = polygon.Vertices[index]
+		}
+	}
+	return convex.parts
+}
+
+/*
+Decompose splits polygon in convex parts, a convex polygon is returned as a
+single part. Polygons ValidatePolygon rejects are only partially covered by
+the parts. Use ConvexParts for a polygon that is tested at many poses
+*/
+func Decompose(polygon CollisionPolygon) []CollisionPolygon {
+	return NewConvexParts(polygon.Vertices).At(polygon)
+}
+
+// project returns the range polygon covers along axis
+func project(polygon CollisionPolygon, axis vector.Vector2) (float64, float64) {
+	minProj, maxProj := math.Inf(1), math.Inf(-1)
+	for _, vertex := range polygon.Vertices {
+		proj := vertex.Dot(&axis)
+		minProj = min(minProj, proj)
+		maxProj = max(maxProj, proj)
+	}
+	return minProj, maxProj
+}
+
+// hasSeparatingAxis checks the normals of a's edges only, see HasCollidedConvexPolygons
+func hasSeparatingAxis(a, b CollisionPolygon) bool {
+	for current := range a.Vertices {
+		next := (current + 1) % len(a.Vertices)
+		axis := vector.New(
+			-(a.Vertices[next].Y - a.Vertices[current].Y),
+			a.Vertices[next].X-a.Vertices[current].X,
+		)
+		if axis.X == 0 && axis.Y == 0 {
+			continue
+		}
+
+		minA, maxA := project(a, axis)
+		minB, maxB := project(b, axis)
+		if maxA < minB || maxB < minA {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+HasCollidedConvexPolygons is the separating axis test: two convex polygons
+overlap unless the normal of one of their edges separates them. A polygon of
+two vertices works as a segment
+Source: https://www.sevenson.com.au/programming/sat/
+*/
+func HasCollidedConvexPolygons(a, b CollisionPolygon) bool {
+	if len(a.Vertices) == 0 || len(b.Vertices) == 0 {
+		return false
+	}
+	return !hasSeparatingAxis(a, b) && !hasSeparatingAxis(b, a)
+}
+
+// HasCollidedPolygonParts reports whether any convex part of a overlaps any of b, see Decompose
+func HasCollidedPolygonParts(a, b []CollisionPolygon) bool {
+	for _, partA := range a {
+		for _, partB := range b {
+			if HasCollidedConvexPolygons(partA, partB) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasCollidedPolygons works on any simple polygon, decomposing both in convex parts
+func HasCollidedPolygons(a, b CollisionPolygon) bool {
+	return HasCollidedPolygonParts(Decompose(a), Decompose(b))
+}
@@ -0,0 +1,69 @@
+package collision
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+func TestValidatePolygon(t *testing.T) {
+	tests := []struct {
+		name     string
+		vertices []vector.Vector2
+		err      error
+	}{
+		{"triangle", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, nil},
+		{"counterclockwise square", []vector.Vector2{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 0}}, nil},
+		{"concave", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 5, Y: 3}, {X: 0, Y: 10}}, nil},
+		{"collinear vertex", []vector.Vector2{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}}, nil},
+		{"empty", nil, ErrTooFewVertices},
+		{"segment", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}}, ErrTooFewVertices},
+		{"collinear", []vector.Vector2{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 10, Y: 0}}, ErrZeroArea},
+		{"bow tie", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 10, Y: 0}, {X: 0, Y: 20}}, ErrSelfIntersecting},
+		{"repeated vertex", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, ErrSelfIntersecting},
+		{"spike", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 0}, {X: 5, Y: 0}, {X: 0, Y: 10}}, ErrSelfIntersecting},
+		{"touching vertex", []vector.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 5}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 5, Y: 5}}, ErrSelfIntersecting},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePolygon(test.vertices)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+			if err == nil && len(Triangulate(test.vertices)) != len(test.vertices)-2 {
+				t.Errorf("valid polygon was only partially triangulated: %v", Triangulate(test.vertices))
+			}
+		})
+	}
+}
+
+func TestConvexPartsFollowPolygon(t *testing.T) {
+	concave := polygon(0, 0, 100, 0, 100, 100, 70, 100, 70, 30, 30, 30, 30, 100, 0, 100)
+	parts := NewConvexParts(concave.Vertices)
+
+	// moved, scaled and mirrored
+	transform := func(vertex vector.Vector2) vector.Vector2 {
+		return vector.New(500+vertex.X*.5, 300-vertex.Y*.5)
+	}
+	moved := CollisionPolygon{Vertices: make([]vector.Vector2, len(concave.Vertices))}
+	for i, vertex := range concave.Vertices {
+		moved.Vertices[i] = transform(vertex)
+	}
+
+	got, want := parts.At(moved), Decompose(concave)
+	if len(got) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(got), len(want))
+	}
+	for i := range got {
+		for j := range got[i].Vertices {
+			if got[i].Vertices[j] != transform(want[i].Vertices[j]) {
+				t.Fatalf("part %d is %v, want %v transformed", i, got[i].Vertices, want[i].Vertices)
+			}
+		}
+		if !isConvex(got[i].Vertices) {
+			t.Errorf("part %d is not convex: %v", i, got[i].Vertices)
+		}
+	}
+}
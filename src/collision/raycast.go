@@ -0,0 +1,147 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+// Ray is a segment cast from Origin, Direction has unit length
+type Ray struct {
+	Origin    vector.Vector2
+	Direction vector.Vector2
+	Length    float64
+}
+
+// NewRay casts a ray from from to to, a zero length ray points right
+func NewRay(from, to vector.Vector2) Ray {
+	direction := to.SubOut(from)
+	length := direction.Magnitude()
+	if length == 0 {
+		return Ray{Origin: from, Direction: vector.New(1, 0)}
+	}
+	return Ray{Origin: from, Direction: direction.DivScalar(length), Length: length}
+}
+
+func (ray Ray) At(distance float64) vector.Vector2 {
+	return ray.Origin.AddOut(ray.Direction.MulScalar(distance))
+}
+
+func (ray Ray) End() vector.Vector2 {
+	return ray.At(ray.Length)
+}
+
+func (ray Ray) Bounds() AABB {
+	return boundsOf([]vector.Vector2{ray.Origin, ray.End()})
+}
+
+/*
+RayHit is where a ray first meets a shape. Normal has unit length and faces
+the ray, so it can be fed to vector.Reflect. A ray starting inside a shape
+hits it at Distance 0, with Normal pointing back along the ray
+*/
+type RayHit struct {
+	Point    vector.Vector2
+	Normal   vector.Vector2
+	Distance float64
+}
+
+func (ray Ray) insideHit() RayHit {
+	return RayHit{Point: ray.Origin, Normal: ray.Direction.MulScalar(-1)}
+}
+
+/*
+raycastSegment uses lineLine's intersection parameters, uA being how far along
+the ray the segment is hit. A ray running along the segment meets its nearest
+end head on, so the normal points back along the ray
+*/
+func raycastSegment(ray Ray, a, b vector.Vector2) (RayHit, bool) {
+	end := ray.End()
+	uA, uB, parallel := lineLineParams(ray.Origin.X, ray.Origin.Y, end.X, end.Y, a.X, a.Y, b.X, b.Y)
+	if parallel {
+		start, overlap := collinearOverlap(ray.Origin.X, ray.Origin.Y, end.X, end.Y, a.X, a.Y, b.X, b.Y)
+		if !overlap {
+			return RayHit{}, false
+		}
+		distance := start * ray.Length
+		return RayHit{Point: ray.At(distance), Normal: ray.Direction.MulScalar(-1), Distance: distance}, true
+	}
+	if !(uA >= 0 && uA <= 1 && uB >= 0 && uB <= 1) {
+		return RayHit{}, false
+	}
+
+	normal := vector.New(-(b.Y - a.Y), b.X-a.X)
+	normal = normal.Normalize()
+	if normal.Dot(&ray.Direction) > 0 {
+		normal = normal.MulScalar(-1)
+	}
+
+	distance := uA * ray.Length
+	return RayHit{Point: ray.At(distance), Normal: normal, Distance: distance}, true
+}
+
+func raycastEdges(ray Ray, vertices []vector.Vector2, closed bool) (RayHit, bool) {
+	var (
+		first RayHit
+		found bool
+	)
+	edges := len(vertices) - 1
+	if closed && len(vertices) > 2 {
+		edges = len(vertices)
+	}
+	for current := 0; current < edges; current++ {
+		next := (current + 1) % len(vertices)
+		hit, ok := raycastSegment(ray, vertices[current], vertices[next])
+		if ok && (!found || hit.Distance < first.Distance) {
+			first, found = hit, true
+		}
+	}
+	return first, found
+}
+
+// RaycastPolygon works on any simple polygon, a polygon of two vertices being a segment
+func RaycastPolygon(ray Ray, polygon CollisionPolygon) (RayHit, bool) {
+	if len(polygon.Vertices) > 2 && PointInPolygon(ray.Origin, polygon) {
+		return ray.insideHit(), true
+	}
+	return raycastEdges(ray, polygon.Vertices, true)
+}
+
+// RaycastPolyline is RaycastPolygon for an open chain of segments, the last vertex not joining the first
+func RaycastPolyline(ray Ray, vertices []vector.Vector2) (RayHit, bool) {
+	return raycastEdges(ray, vertices, false)
+}
+
+func RaycastRect(ray Ray, rect CollisionRect) (RayHit, bool) {
+	if PointInRect(ray.Origin, rect) {
+		return ray.insideHit(), true
+	}
+	return raycastEdges(ray, rect.Vertices(), true)
+}
+
+/*
+RaycastCircle solves |origin + t*direction - center| = radius for the
+smallest t along the ray
+*/
+func RaycastCircle(ray Ray, circle CollisionCircle) (RayHit, bool) {
+	if PointInCircle(ray.Origin, circle) {
+		return ray.insideHit(), true
+	}
+
+	toOrigin := ray.Origin.SubOut(circle.Center)
+	b := toOrigin.Dot(&ray.Direction)
+	c := toOrigin.Dot(&toOrigin) - circle.Radius*circle.Radius
+	discriminant := b*b - c
+	if discriminant < 0 {
+		return RayHit{}, false
+	}
+
+	distance := -b - math.Sqrt(discriminant)
+	if distance < 0 || distance > ray.Length {
+		return RayHit{}, false
+	}
+
+	point := ray.At(distance)
+	normal := point.SubOut(circle.Center)
+	return RayHit{Point: point, Normal: normal.DivScalar(circle.Radius), Distance: distance}, true
+}
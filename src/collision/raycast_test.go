@@ -0,0 +1,161 @@
+package collision
+
+import (
+	"math"
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+func ray(fromX, fromY, toX, toY float64) Ray {
+	return NewRay(vector.New(fromX, fromY), vector.New(toX, toY))
+}
+
+func rayHit(x, y, normalX, normalY, distance float64) RayHit {
+	return RayHit{Point: vector.New(x, y), Normal: vector.New(normalX, normalY), Distance: distance}
+}
+
+// sameHit compares hits allowing for rounding
+func sameHit(a, b RayHit) bool {
+	return near(a.Point, b.Point) && near(a.Normal, b.Normal) && math.Abs(a.Distance-b.Distance) < epsilon
+}
+
+func TestRaycastPolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		ray     Ray
+		polygon CollisionPolygon
+		want    RayHit
+		hit     bool
+	}{
+		{"nearest face", ray(-50, 50, 200, 50), box100, rayHit(0, 50, -1, 0, 50), true},
+		{"nearest face from the other side", ray(200, 50, -50, 50), box100, rayHit(100, 50, 1, 0, 100), true},
+		{"passing by", ray(-50, -10, 200, -10), box100, RayHit{}, false},
+		{"too short", ray(-50, 50, -10, 50), box100, RayHit{}, false},
+		{"origin inside", ray(50, 50, 200, 50), box100, rayHit(50, 50, -1, 0, 0), true},
+		{"concave notch", ray(50, 150, 50, -50), testConcave, rayHit(50, 30, 0, 1, 120), true},
+		{"along an edge", ray(-50, 0, 200, 0), box100, rayHit(0, 0, -1, 0, 50), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, hit := RaycastPolygon(test.ray, test.polygon); hit != test.hit || (hit && !sameHit(got, test.want)) {
+				t.Errorf("RaycastPolygon(%+v, %v) = %+v, %v, want %+v, %v", test.ray, test.polygon.Vertices, got, hit, test.want, test.hit)
+			}
+		})
+	}
+}
+
+func TestRaycastRect(t *testing.T) {
+	tests := []struct {
+		name string
+		ray  Ray
+		rect CollisionRect
+		want RayHit
+		hit  bool
+	}{
+		{"face", ray(50, -50, 50, 100), testRect, rayHit(50, 0, 0, -1, 50), true},
+		{"rotated", ray(0, 0, 100, 0), testRotatedRect, rayHit(45, 0, -1, 0, 45), true},
+		{"outside rotated, inside unrotated", ray(0, 8, 40, 8), testRotatedRect, RayHit{}, false},
+		{"origin inside", ray(50, 25, 50, 100), testRect, rayHit(50, 25, 0, -1, 0), true},
+		{"along an edge", ray(-50, 50, 200, 50), testRect, rayHit(0, 50, -1, 0, 50), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, hit := RaycastRect(test.ray, test.rect); hit != test.hit || (hit && !sameHit(got, test.want)) {
+				t.Errorf("RaycastRect(%+v, %+v) = %+v, %v, want %+v, %v", test.ray, test.rect, got, hit, test.want, test.hit)
+			}
+		})
+	}
+}
+
+func TestRaycastPolyline(t *testing.T) {
+	chain := polygon(0, 0, 100, 0, 100, 100, 0, 100).Vertices
+	wall := polygon(100, 0, 200, 0).Vertices
+
+	tests := []struct {
+		name     string
+		ray      Ray
+		vertices []vector.Vector2
+		want     RayHit
+		hit      bool
+	}{
+		{"first segment hit", ray(50, -50, 50, 200), chain, rayHit(50, 0, 0, -1, 50), true},
+		{"first segment hit, reversed", ray(50, 200, 50, -50), chain, rayHit(50, 100, 0, 1, 100), true},
+		{"open end", ray(-50, 50, 50, 50), chain, RayHit{}, false},
+		{"origin inside the outline", ray(50, 50, 200, 50), chain, rayHit(100, 50, -1, 0, 50), true},
+		{"along the wall", ray(0, 0, 300, 0), wall, rayHit(100, 0, -1, 0, 100), true},
+		{"along the wall, reversed", ray(300, 0, 0, 0), wall, rayHit(200, 0, 1, 0, 100), true},
+		{"along the wall, starting on it", ray(150, 0, 300, 0), wall, rayHit(150, 0, -1, 0, 0), true},
+		{"along the wall, too short", ray(0, 0, 99, 0), wall, RayHit{}, false},
+		{"along the wall, past it", ray(300, 0, 400, 0), wall, RayHit{}, false},
+		{"parallel to the wall", ray(0, 1, 300, 1), wall, RayHit{}, false},
+		{"zero length on the wall", ray(150, 0, 150, 0), wall, rayHit(150, 0, -1, 0, 0), true},
+		{"zero length beside the wall", ray(150, 1, 150, 1), wall, RayHit{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, hit := RaycastPolyline(test.ray, test.vertices); hit != test.hit || (hit && !sameHit(got, test.want)) {
+				t.Errorf("RaycastPolyline(%+v, %v) = %+v, %v, want %+v, %v", test.ray, test.vertices, got, hit, test.want, test.hit)
+			}
+		})
+	}
+}
+
+func TestRaycastCircle(t *testing.T) {
+	target := circle(100, 0, 10)
+
+	tests := []struct {
+		name   string
+		ray    Ray
+		circle CollisionCircle
+		want   RayHit
+		hit    bool
+	}{
+		{"head on", ray(0, 0, 200, 0), target, rayHit(90, 0, -1, 0, 90), true},
+		{"off center", ray(0, 6, 200, 6), target, rayHit(92, 6, -.8, .6, 92), true},
+		{"tangent", ray(0, 10, 200, 10), target, rayHit(100, 10, 0, 1, 100), true},
+		{"passing by", ray(0, 11, 200, 11), target, RayHit{}, false},
+		{"behind", ray(200, 0, 300, 0), target, RayHit{}, false},
+		{"too short", ray(0, 0, 89, 0), target, RayHit{}, false},
+		{"origin inside", ray(100, 5, 200, 5), target, rayHit(100, 5, -1, 0, 0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, hit := RaycastCircle(test.ray, test.circle); hit != test.hit || (hit && !sameHit(got, test.want)) {
+				t.Errorf("RaycastCircle(%+v, %+v) = %+v, %v, want %+v, %v", test.ray, test.circle, got, hit, test.want, test.hit)
+			}
+		})
+	}
+}
+
+func TestLineLineParallel(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, y1, x2, y2 float64
+		x3, y3, x4, y4 float64
+		want           bool
+	}{
+		{"collinear overlapping", 0, 0, 10, 0, 5, 0, 15, 0, true},
+		{"collinear containing", 0, 0, 10, 0, 2, 0, 8, 0, true},
+		{"collinear touching", 0, 0, 10, 0, 10, 0, 20, 0, true},
+		{"collinear opposite directions", 0, 0, 10, 10, 15, 15, 5, 5, true},
+		{"collinear disjoint", 0, 0, 10, 0, 11, 0, 20, 0, false},
+		{"parallel", 0, 0, 10, 0, 0, 1, 10, 1, false},
+		{"point on line", 5, 0, 5, 0, 0, 0, 10, 0, true},
+		{"point off line", 5, 1, 5, 1, 0, 0, 10, 0, false},
+		{"point past line", 11, 0, 11, 0, 0, 0, 10, 0, false},
+		{"crossing", 0, 0, 10, 10, 0, 10, 10, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := lineLine(test.x1, test.y1, test.x2, test.y2, test.x3, test.y3, test.x4, test.y4); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
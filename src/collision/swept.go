@@ -0,0 +1,87 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+const (
+	// sweepStepFactor is how much of the polygon's smallest side a sub-step may move, below 1 so thin walls can't be skipped
+	sweepStepFactor = .5
+	// sweepRefinements is how many times the time of impact is halved, 2^-10 of a tick is plenty
+	sweepRefinements = 10
+)
+
+// LerpPolygon moves every vertex of dst from from to to, the three polygons must have as many vertices
+func LerpPolygon(dst *CollisionPolygon, from, to CollisionPolygon, t float64) {
+	for i := range dst.Vertices {
+		dst.Vertices[i] = from.Vertices[i].Lerp(&to.Vertices[i], t)
+	}
+}
+
+// smallestExtent is the smallest side of the polygon's bounding box
+func smallestExtent(polygon CollisionPolygon) float64 {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, vertex := range polygon.Vertices {
+		minX, maxX = min(minX, vertex.X), max(maxX, vertex.X)
+		minY, maxY = min(minY, vertex.Y), max(maxY, vertex.Y)
+	}
+	return min(maxX-minX, maxY-minY)
+}
+
+/*
+Sweep finds when a polygon moving from `from` to `to` during a tick first
+hits something, hit being the static collision test at a given pose. The
+motion is split in sub-steps short enough for the polygon never to jump over
+anything, whatever its size or speed, and the time of impact is then refined
+by bisection. It returns the time of impact in [0, 1], 1 being the end of the
+tick, and whether anything was hit at all. The polygon rotating or scaling
+during the tick is handled, as every vertex is interpolated on its own
+*/
+func Sweep(from, to CollisionPolygon, hit func(CollisionPolygon) bool) (float64, bool) {
+	if len(from.Vertices) != len(to.Vertices) || len(to.Vertices) == 0 {
+		return 1, hit(to)
+	}
+
+	maxMove := 0.0
+	for i := range to.Vertices {
+		maxMove = max(maxMove, from.Vertices[i].Distance(to.Vertices[i]))
+	}
+
+	steps := 1
+	if maxStep := smallestExtent(to) * sweepStepFactor; maxStep > 0 {
+		steps = max(1, int(math.Ceil(maxMove/maxStep)))
+	}
+
+	pose := CollisionPolygon{Vertices: make([]vector.Vector2, len(to.Vertices))}
+	hitAt := func(t float64) bool {
+		if t == 1 {
+			return hit(to)
+		}
+		LerpPolygon(&pose, from, to, t)
+		return hit(pose)
+	}
+
+	for step := 1; step <= steps; step++ {
+		t := float64(step) / float64(steps)
+		if !hitAt(t) {
+			continue
+		}
+
+		// the impact is between the previous sub-step, which was clear, and this one
+		clear, hitting := float64(step-1)/float64(steps), t
+		for i := 0; i < sweepRefinements; i++ {
+			middle := (clear + hitting) / 2
+			if hitAt(middle) {
+				hitting = middle
+			} else {
+				clear = middle
+			}
+		}
+		return hitting, true
+	}
+
+	return 1, false
+}
@@ -0,0 +1,77 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+func square(center vector.Vector2, size float64) CollisionPolygon {
+	half := size / 2
+	return polygon(
+		center.X-half, center.Y-half,
+		center.X+half, center.Y-half,
+		center.X+half, center.Y+half,
+		center.X-half, center.Y+half,
+	)
+}
+
+func TestSweepThinWall(t *testing.T) {
+	// 2 pixels thick, far thinner than how far the ships move in a tick
+	wall := CollisionRect{Pos: vector.New(100, -100), W: 2, H: 200}
+	hitsWall := func(polygon CollisionPolygon) bool {
+		return HasCollidedRectPolygon(wall, polygon)
+	}
+
+	tests := []struct {
+		name     string
+		size     float64
+		from, to float64
+	}{
+		{"small ship", 4, 0, 200},
+		{"large ship", 60, 0, 400},
+		{"small ship backwards", 4, 200, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from := square(vector.New(test.from, 0), test.size)
+			to := square(vector.New(test.to, 0), test.size)
+			if hitsWall(from) || hitsWall(to) {
+				t.Fatal("the ship should only touch the wall in between the two poses")
+			}
+
+			toi, hit := Sweep(from, to, hitsWall)
+			if !hit {
+				t.Fatal("the ship tunnelled through the wall")
+			}
+
+			// the side facing the wall reaches it at the time of impact
+			contact := wall.Pos.X - test.size/2
+			if test.from > test.to {
+				contact = wall.Pos.X + wall.W + test.size/2
+			}
+			want := (contact - test.from) / (test.to - test.from)
+			if toi < want || toi > want+.01 {
+				t.Errorf("time of impact %v, want %v", toi, want)
+			}
+
+			impact := from
+			impact.Vertices = make([]vector.Vector2, len(from.Vertices))
+			LerpPolygon(&impact, from, to, toi)
+			if !hitsWall(impact) {
+				t.Error("the ship does not touch the wall at the time of impact")
+			}
+		})
+	}
+}
+
+func TestSweepMisses(t *testing.T) {
+	wall := CollisionRect{Pos: vector.New(100, 50), W: 2, H: 200}
+	toi, hit := Sweep(square(vector.New(0, 0), 4), square(vector.New(200, 0), 4), func(polygon CollisionPolygon) bool {
+		return HasCollidedRectPolygon(wall, polygon)
+	})
+	if hit || toi != 1 {
+		t.Errorf("got a hit at %v passing beside the wall", toi)
+	}
+}
@@ -1,3 +1,5 @@
 package constants
 
 const Width, Height = 640, 480
+
+const GameVersion = "1.0.0"
@@ -0,0 +1,6 @@
+//go:build dev
+
+package constants
+
+// DevBuild is set by building with -tags dev, it unlocks the debug options in game
+const DevBuild = true
@@ -0,0 +1,5 @@
+//go:build !dev
+
+package constants
+
+const DevBuild = false
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"github.com/abelroes/gmtk2024/src/simulation"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const ghostAlpha = .35
+
+// Ghost replays a recorded trajectory as a translucent ship
+type Ghost struct {
+	Frames []simulation.GhostFrame
+	img    *ebiten.Image
+}
+
+func NewGhost(img *ebiten.Image, frames []simulation.GhostFrame) *Ghost {
+	return &Ghost{
+		Frames: frames,
+		img:    img,
+	}
+}
+
+// Draw draws the ship as it was on the given tick of the recorded attempt
+func (ghost *Ghost) Draw(screen *ebiten.Image, tick int, camera Camera) {
+	if tick < 0 || tick >= len(ghost.Frames) {
+		return
+	}
+	frame := ghost.Frames[tick]
+
+	bounds := ghost.img.Bounds()
+	w, h := float64(bounds.Dx())*frame.Scale, float64(bounds.Dy())*frame.Scale
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(frame.Scale, frame.Scale)
+	op.GeoM.Translate(-w/2, -h/2)
+	op.GeoM.Rotate(-frame.Rot)
+	op.GeoM.Translate(camera.X, -camera.Y)
+	op.GeoM.Translate(frame.Pos.X, frame.Pos.Y)
+	op.ColorScale.ScaleAlpha(ghostAlpha)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(ghost.img, op)
+}
@@ -3,64 +3,39 @@ package entity
 import (
 	"image/color"
 
-	"github.com/abelroes/gmtk2024/src/collision"
 	"github.com/abelroes/gmtk2024/src/settings"
-	"github.com/abelroes/gmtk2024/src/vector"
+	"github.com/abelroes/gmtk2024/src/simulation"
 	"github.com/hajimehoshi/ebiten/v2"
 	ebivector "github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// Goal draws the simulation's black hole, spinning
 type Goal struct {
-	Pos           vector.Vector2
-	Radius        float64
-	Collider      collision.CollisionRect
 	img           *ebiten.Image
-	angle         float64
 	debugSettings *settings.SettingsDebug
 }
 
-const (
-	rotationSpeed = .01
-)
-
-func NewGoal(img *ebiten.Image, debugSettings *settings.SettingsDebug, radius float64) Goal {
-	return Goal{
-		Radius:        radius,
+func NewGoal(img *ebiten.Image, debugSettings *settings.SettingsDebug) *Goal {
+	return &Goal{
 		img:           img,
 		debugSettings: debugSettings,
 	}
 }
 
-func (goal *Goal) SetPos(pos vector.Vector2) {
-	goal.angle = 0
-	goal.Pos = pos
-	colliderFactor := .75
-	r := goal.Radius * colliderFactor
-	goal.Collider = collision.CollisionRect{
-		Pos: vector.New(goal.Pos.X-r, goal.Pos.Y-r),
-		W:   2 * r,
-		H:   2 * r,
-	}
-}
-
-func (goal *Goal) Update() {
-	goal.angle += rotationSpeed
-}
-
-func (goal *Goal) Draw(screen *ebiten.Image) {
+func (sprite *Goal) Draw(screen *ebiten.Image, goal *simulation.Goal) {
 
-	bounds := goal.img.Bounds()
+	bounds := sprite.img.Bounds()
 	w, h := float64(bounds.Dx()), float64(bounds.Dy())
 
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(-w/2, -h/2)
 	op.GeoM.Scale((goal.Radius*2)/w, (goal.Radius*2)/h)
-	op.GeoM.Rotate(goal.angle)
+	op.GeoM.Rotate(goal.Angle)
 	op.GeoM.Translate(goal.Pos.X, goal.Pos.Y)
 	op.Filter = ebiten.FilterLinear
-	screen.DrawImage(goal.img, op)
+	screen.DrawImage(sprite.img, op)
 
-	if goal.debugSettings.GoalHitbox {
-		ebivector.DrawFilledRect(screen, float32(goal.Collider.Pos.X), float32(goal.Collider.Pos.Y), float32(goal.Collider.W), float32(goal.Collider.H), color.RGBA{R: 255}, true)
+	if sprite.debugSettings.GoalHitbox {
+		ebivector.DrawFilledCircle(screen, float32(goal.Collider.Center.X), float32(goal.Collider.Center.Y), float32(goal.Collider.Radius), color.RGBA{R: 255}, true)
 	}
 }
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/abelroes/gmtk2024/src/simulation"
+	"github.com/abelroes/gmtk2024/src/vector"
+	"github.com/hajimehoshi/ebiten/v2"
+	ebivector "github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const obstacleOutlineWidth = 3
+
+var (
+	obstacleFillColor    = color.RGBA{R: 0x5a, G: 0x4b, B: 0x43, A: 0xff}
+	obstacleOutlineColor = color.RGBA{R: 0xa8, G: 0x93, B: 0x82, A: 0xff}
+	// whiteImage is the texture DrawTriangles fills the obstacles with, created on first draw
+	whiteImage *ebiten.Image
+)
+
+// DrawObstacle fills closed obstacles and outlines every obstacle
+func DrawObstacle(screen *ebiten.Image, obstacle *simulation.Obstacle) {
+	if obstacle.Closed {
+		drawObstacleFill(screen, obstacle.Vertices)
+	}
+
+	for i := 0; i+1 < len(obstacle.Vertices); i++ {
+		drawObstacleEdge(screen, obstacle.Vertices[i], obstacle.Vertices[i+1])
+	}
+	if obstacle.Closed {
+		drawObstacleEdge(screen, obstacle.Vertices[len(obstacle.Vertices)-1], obstacle.Vertices[0])
+	}
+}
+
+func drawObstacleEdge(screen *ebiten.Image, from, to vector.Vector2) {
+	ebivector.StrokeLine(screen, float32(from.X), float32(from.Y), float32(to.X), float32(to.Y), obstacleOutlineWidth, obstacleOutlineColor, true)
+}
+
+func drawObstacleFill(screen *ebiten.Image, vertices []vector.Vector2) {
+	if whiteImage == nil {
+		whiteImage = ebiten.NewImage(3, 3)
+		whiteImage.Fill(color.White)
+	}
+
+	var path ebivector.Path
+	for i, vertex := range vertices {
+		if i == 0 {
+			path.MoveTo(float32(vertex.X), float32(vertex.Y))
+		} else {
+			path.LineTo(float32(vertex.X), float32(vertex.Y))
+		}
+	}
+	path.Close()
+
+	triangles, indices := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	r, g, b, a := obstacleFillColor.RGBA()
+	for i := range triangles {
+		triangles[i].SrcX, triangles[i].SrcY = 1, 1
+		triangles[i].ColorR = float32(r) / 0xffff
+		triangles[i].ColorG = float32(g) / 0xffff
+		triangles[i].ColorB = float32(b) / 0xffff
+		triangles[i].ColorA = float32(a) / 0xffff
+	}
+
+	op := &ebiten.DrawTrianglesOptions{AntiAlias: true}
+	screen.DrawTriangles(triangles, indices, whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image), op)
+}
@@ -1,52 +1,20 @@
 package entity
 
 import (
-	_ "embed"
 	"image/color"
 	"math"
-	"time"
 
-	"github.com/abelroes/gmtk2024/src/collision"
-	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/clock"
 	"github.com/abelroes/gmtk2024/src/settings"
-	"github.com/abelroes/gmtk2024/src/vector"
+	"github.com/abelroes/gmtk2024/src/simulation"
 	"github.com/hajimehoshi/ebiten/v2"
 	ebivector "github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-const (
-	initialManeuverability = 0.1
-	airFriction            = 0.1
-	scaleFactor            = 0.005
-	initialScale           = .5
-	constantPropulsion     = 0.0
-	minimumScale           = 0.03
-	oobMargin              = 50
-)
-
-type PlayerEvent int
-
-const (
-	PlayerDiedByCollision = iota
-	PlayerDiedByShrinking
-	PlayerDiedByOutOfBounds
-)
-
+// Player draws the simulation's ship and the thrust particles it leaves behind
 type Player struct {
-	Dead            bool
-	Scale           float64
-	Rot             float64
-	Propulsion      float64
-	Pos             vector.Vector2
-	Vel             vector.Vector2
-	Acl             vector.Vector2
-	Maneuverability float64
-	MinimumScale    float64
-	Collisor        collision.CollisionPolygon
-	basePolygon     []vector.Vector2
-	eventHandler    func(PlayerEvent)
-	bounds          collision.CollisionRect
-	debugSettings   *settings.SettingsDebug
+	debugSettings *settings.SettingsDebug
+	clock         *clock.Clock
 
 	//NOTE: this is allocating more memory than needed
 	thrustParticles []ThrustParticle
@@ -54,149 +22,37 @@ type Player struct {
 	img *ebiten.Image
 }
 
-func NewPlayer(img *ebiten.Image, debugSettings *settings.SettingsDebug, basePolygon []vector.Vector2, eventHandler func(PlayerEvent)) Player {
+func NewPlayer(img *ebiten.Image, clock *clock.Clock, debugSettings *settings.SettingsDebug) Player {
 	return Player{
-		Scale:           initialScale,
 		img:             img,
-		Maneuverability: initialManeuverability,
 		thrustParticles: make([]ThrustParticle, 0, 1000),
-		Collisor:        collision.CollisionPolygon{Vertices: make([]vector.Vector2, len(basePolygon))},
-		basePolygon:     basePolygon,
-		MinimumScale:    minimumScale,
-		eventHandler:    eventHandler,
-		bounds: collision.CollisionRect{
-			Pos: vector.New(-oobMargin, -oobMargin),
-			W:   constants.Width + (oobMargin * 2),
-			H:   constants.Height + (oobMargin * 2),
-		},
-		debugSettings: debugSettings,
-	}
-}
-
-func (player *Player) Reset() {
-	player.Dead = false
-	player.Rot = 0
-	player.Vel.Set(0, 0)
-	player.Acl.Set(0, 0)
-	player.Scale = initialScale
-}
-
-func (player *Player) updateCollider(sin, cos float64) {
-	for i := 0; i < len(player.Collisor.Vertices); i++ {
-		var (
-			base = player.basePolygon[i]
-			// vector rotation
-			x = cos*base.X - sin*base.Y
-			y = sin*base.X + cos*base.Y
-		)
-
-		player.Collisor.Vertices[i] = vector.Vector2{
-			X: (player.Pos.X + x*player.Scale),
-			Y: (player.Pos.Y - y*player.Scale),
-		}
+		debugSettings:   debugSettings,
+		clock:           clock,
 	}
 }
 
-func (player *Player) DieByCollision() {
-	player.die(PlayerDiedByCollision)
-}
-
-func (player *Player) die(event PlayerEvent) {
-	player.Dead = true
-	player.eventHandler(event)
-}
-
-func (player *Player) GetDimensions() (float64, float64) {
-	bounds := player.img.Bounds()
-	w, h := float64(bounds.Dx()), float64(bounds.Dy())
-	w *= player.Scale
-	h *= player.Scale
-
-	return w, h
-}
-
-func (player *Player) GetTopLeftPos() vector.Vector2 {
-	w, h := player.GetDimensions()
-	return vector.Vector2{
-		X: player.Pos.X - w/2,
-		Y: player.Pos.Y - h/2,
-	}
-}
-
-func (player *Player) Update() {
+// Update moves the particles, it runs once per simulation tick, after the ship moved
+func (player *Player) Update(ship *simulation.Player) {
 	player.updateThrustParticles()
 
-	if player.Dead {
-		return
-	}
-
-	if player.checkOob() {
-		return
-	}
-
-	w, h := player.GetDimensions()
-
-	if player.Scale <= player.MinimumScale {
-		player.die(PlayerDiedByShrinking)
-		return
-	}
-
-	sin, cos := math.Sincos(player.Rot)
-
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
-		player.Rot += player.Maneuverability
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
-		player.Rot -= player.Maneuverability
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyW) || ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeySpace) {
-		player.Propulsion = 1.0
-
-		vol := 5
+	if ship.Thrust > 0 {
+		// particles come out of where the ship was when the tick started
+		w, h := ship.GetDimensions()
+		vol := int(math.Ceil(5 * ship.Thrust))
 		for i := 0; i < vol; i++ {
-			player.thrustParticles = append(player.thrustParticles, SpawnParticle(w, h, player.Rot, player.Pos.X, player.Pos.Y))
+			player.thrustParticles = append(player.thrustParticles, SpawnParticle(player.clock.Now(), w, h, ship.Rot, ship.PrevPos.X, ship.PrevPos.Y))
 		}
 	}
-
-	if player.Propulsion > 0.0 {
-		player.Acl.Set(player.Propulsion*cos, -player.Propulsion*sin)
-		player.Scale = max(0, player.Scale-(player.Propulsion*scaleFactor))
-	}
-
-	player.Vel.Add(player.Acl)
-	player.Pos.Add(player.Vel)
-	player.Acl.Set(0, 0)
-	player.Propulsion = constantPropulsion
-
-	player.Vel.Sub(player.Vel.MulScalar(airFriction))
-
-	player.updateCollider(sin, cos)
-}
-
-func (player *Player) checkOob() bool {
-	var (
-		pos    = player.Pos
-		bounds = player.bounds
-
-		horizontal = pos.X < bounds.Pos.X || pos.X >= bounds.Pos.X+bounds.W
-		vertical   = pos.Y < bounds.Pos.Y || pos.Y >= bounds.Pos.Y+bounds.H
-		isOob      = horizontal || vertical
-	)
-	if isOob {
-		player.die(PlayerDiedByOutOfBounds)
-		return true
-	}
-	return false
 }
 
 func (player *Player) updateThrustParticles() {
 	//NOTE: Probably refact to Particles or smthing like that
-	now := time.Now()
+	now := player.clock.Now()
 
 	lastDead := -1
 	for i := len(player.thrustParticles) - 1; i >= 0; i-- {
 		particle := player.thrustParticles[i]
-		isDead := now.Sub(particle.start) > particleThrustTtl
+		isDead := now-particle.start > particleThrustTtl
 		if isDead {
 			lastDead = i
 			break
@@ -212,38 +68,38 @@ func (player *Player) updateThrustParticles() {
 	}
 }
 
-func (player *Player) Draw(screen *ebiten.Image, camera Camera) {
-	now := time.Now()
+func (player *Player) Draw(screen *ebiten.Image, ship *simulation.Player, camera Camera) {
+	now := player.clock.Now()
 	for _, particle := range player.thrustParticles {
 		particle.Draw(screen, now)
 	}
 
-	if player.Dead {
+	if ship.Dead {
 		return
 	}
 
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(player.Scale, player.Scale)
-	w, h := player.GetDimensions()
+	op.GeoM.Scale(ship.Scale, ship.Scale)
+	w, h := ship.GetDimensions()
 
 	// center position based on the image * scale
 	op.GeoM.Translate(-w/2, -h/2)
 	// rotate
-	op.GeoM.Rotate(-player.Rot)
+	op.GeoM.Rotate(-ship.Rot)
 	// shift position based on camera position
 	op.GeoM.Translate(camera.X, -camera.Y)
 	// position image based on player position
-	op.GeoM.Translate(player.Pos.X, player.Pos.Y)
+	op.GeoM.Translate(ship.Pos.X, ship.Pos.Y)
 	op.Filter = ebiten.FilterLinear
 	screen.DrawImage(player.img, op)
 
 	if player.debugSettings.PlayerHitbox {
-		player.drawPolygonDebugHitBox(screen)
+		player.drawPolygonDebugHitBox(screen, ship)
 	}
 }
 
-func (player *Player) drawPolygonDebugHitBox(screen *ebiten.Image) {
-	polygon := player.Collisor
+func (player *Player) drawPolygonDebugHitBox(screen *ebiten.Image, ship *simulation.Player) {
+	polygon := ship.Collisor
 
 	next := 0
 	verticesQtd := len(polygon.Vertices)
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+var (
+	aheadColor  = color.RGBA{G: 220, B: 80, A: 255}
+	behindColor = color.RGBA{R: 230, G: 60, B: 60, A: 255}
+)
+
+type Split struct {
+	Name  string
+	Time  time.Duration
+	Delta time.Duration
+	// HasDelta is false when there is no personal best to compare with
+	HasDelta bool
+}
+
+func NewSplit(name string, t, best time.Duration, hasBest bool) Split {
+	return Split{
+		Name:     name,
+		Time:     t,
+		Delta:    t - best,
+		HasDelta: hasBest,
+	}
+}
+
+// FormatDuration formats d as m:ss.cc
+func FormatDuration(d time.Duration) string {
+	centiseconds := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d.%02d", centiseconds/6000, (centiseconds/100)%60, centiseconds%100)
+}
+
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, d.Milliseconds()/1000, (d.Milliseconds()/10)%100)
+}
+
+// DrawSplits draws the speedrun timer on the top right corner, one line per split
+func (ui *Ui) DrawSplits(screen *ebiten.Image, splits []Split) {
+	const (
+		size       = 11
+		lineHeight = 14
+		right      = constants.Width - 10
+		top        = 28
+	)
+
+	for i, split := range splits {
+		y := float64(top + i*lineHeight)
+		ui.drawText(screen, fmt.Sprintf("%s  %s", split.Name, FormatDuration(split.Time)), size, right-55, y, text.AlignEnd, color.White)
+
+		if split.HasDelta {
+			deltaColor := behindColor
+			if split.Delta < 0 {
+				deltaColor = aheadColor
+			}
+			ui.drawText(screen, formatDelta(split.Delta), size, right, y, text.AlignEnd, deltaColor)
+		}
+	}
+}
@@ -4,14 +4,14 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-	"time"
 
+	"github.com/abelroes/gmtk2024/src/clock"
 	"github.com/abelroes/gmtk2024/src/vector"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const (
-	particleThrustTtl = time.Duration(1 * time.Second)
+	particleThrustTtl = clock.Tick(1 * clock.TicksPerSecond)
 )
 
 var (
@@ -30,16 +30,16 @@ func init() {
 type ThrustParticle struct {
 	pos   vector.Vector2
 	vel   vector.Vector2
-	start time.Time
+	start clock.Tick
 }
 
 func (particle *ThrustParticle) Update() {
 	particle.pos.Add(particle.vel)
 }
 
-func (particle *ThrustParticle) Draw(screen *ebiten.Image, now time.Time) {
+func (particle *ThrustParticle) Draw(screen *ebiten.Image, now clock.Tick) {
 	op := &ebiten.DrawImageOptions{}
-	timeRemainingRatio := float32(now.Sub(particle.start)) / float32(particleThrustTtl)
+	timeRemainingRatio := float32(now-particle.start) / float32(particleThrustTtl)
 	gradient := (1 - timeRemainingRatio)
 	colorGradient := gradient * gradient * gradient
 	redGradient := gradient
@@ -49,7 +49,7 @@ func (particle *ThrustParticle) Draw(screen *ebiten.Image, now time.Time) {
 	screen.DrawImage(Dot, op)
 }
 
-func SpawnParticle(w, h, rot, x, y float64) ThrustParticle {
+func SpawnParticle(now clock.Tick, w, h, rot, x, y float64) ThrustParticle {
 	s, c := math.Sincos(rot) // NOTE: this is computed in player.Draw, maybe pass to this method instead of recomputing it
 
 	os, oc := math.Sincos(rot - (math.Pi / 2))
@@ -67,6 +67,6 @@ func SpawnParticle(w, h, rot, x, y float64) ThrustParticle {
 	return ThrustParticle{
 		pos:   particlePos,
 		vel:   vector.Vector2{X: -c * vel, Y: s * vel},
-		start: time.Now(),
+		start: now,
 	}
 }
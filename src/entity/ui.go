@@ -1,16 +1,22 @@
 package entity
 
 import (
+	"fmt"
 	"image/color"
+	"strings"
 
 	"github.com/abelroes/gmtk2024/src/constants"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	ebivector "github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 type Ui struct {
 	ShowRestartText bool
-	font            *text.GoTextFaceSource
+	// RestartKeys names what restarts the level in the restart text
+	RestartKeys    string
+	ShowReplayText bool
+	font           *text.GoTextFaceSource
 }
 
 func NewUi(font *text.GoTextFaceSource) *Ui {
@@ -21,15 +27,68 @@ func NewUi(font *text.GoTextFaceSource) *Ui {
 
 func (ui *Ui) Draw(screen *ebiten.Image) {
 	if ui.ShowRestartText {
-		op := &text.DrawOptions{
-			LayoutOptions: text.LayoutOptions{PrimaryAlign: text.AlignCenter},
+		ui.drawText(screen, fmt.Sprintf("You died! press %s to try again", ui.RestartKeys), 15, constants.Width/2, (constants.Height/2)-50, text.AlignCenter, color.White)
+	}
+
+	if ui.ShowReplayText {
+		ui.drawText(screen, "REPLAY", 12, constants.Width-10, 10, text.AlignEnd, color.White)
+	}
+}
+
+const (
+	errorTextSize     = 12
+	errorLineLength   = 90
+	errorPadding      = 8
+	errorLineSpacing  = errorTextSize * 1.3
+	errorOverlayAlpha = 0xd0
+)
+
+// DrawError shows msg over the bottom of the screen, used for errors the game keeps running through
+func (ui *Ui) DrawError(screen *ebiten.Image, msg string) {
+	lines := wrap(msg, errorLineLength)
+	h := float64(len(lines))*errorLineSpacing + 2*errorPadding
+	y := constants.Height - h
+
+	ebivector.DrawFilledRect(screen, 0, float32(y), constants.Width, float32(h), color.RGBA{R: 0x40, A: errorOverlayAlpha}, false)
+	for i, line := range lines {
+		ui.drawText(screen, line, errorTextSize, errorPadding, y+errorPadding+float64(i)*errorLineSpacing, text.AlignStart, color.RGBA{R: 0xff, G: 0x90, B: 0x90, A: 0xff})
+	}
+}
+
+// wrap splits str in lines of at most width runes, breaking at spaces when possible
+func wrap(str string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(str, "\n") {
+		line := []rune{}
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			if len(line) > 0 && len(line)+1+len(runes) > width {
+				lines = append(lines, string(line))
+				line = line[:0]
+			}
+			for len(runes) > width {
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			if len(line) > 0 {
+				line = append(line, ' ')
+			}
+			line = append(line, runes...)
 		}
-		op.GeoM.Translate(constants.Width/2, (constants.Height/2)-50)
+		lines = append(lines, string(line))
+	}
+	return lines
+}
 
-		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, "You died! press Enter or R to try again", &text.GoTextFace{
-			Source: ui.font,
-			Size:   15,
-		}, op)
+func (ui *Ui) drawText(screen *ebiten.Image, str string, size, x, y float64, align text.Align, clr color.Color) {
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{PrimaryAlign: align},
 	}
+	op.GeoM.Translate(x, y)
+
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, str, &text.GoTextFace{
+		Source: ui.font,
+		Size:   size,
+	}, op)
 }
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/abelroes/gmtk2024/src/settings"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// controlsScreen lets the player rebind every device.Action and saves the result to the settings file
+type controlsScreen struct {
+	bindings *device.Bindings
+	settings *settings.Settings
+	saveFunc func() error
+	list     optionList
+	waiting  bool
+	err      error
+}
+
+func newControlsScreen(bindings *device.Bindings, settings *settings.Settings, saveFunc func() error) *controlsScreen {
+	return &controlsScreen{
+		bindings: bindings,
+		settings: settings,
+		saveFunc: saveFunc,
+	}
+}
+
+func (screen *controlsScreen) resetOption() int {
+	return int(device.ActionsCount)
+}
+
+func (screen *controlsScreen) backOption() int {
+	return int(device.ActionsCount) + 1
+}
+
+// update returns true once the player leaves the screen
+func (screen *controlsScreen) update() bool {
+	if screen.waiting {
+		if screen.bindings.IsJustPressed(device.ActionMenuBack) {
+			screen.waiting = false
+			return false
+		}
+
+		binding, pressed := device.CaptureJustPressed()
+		if pressed {
+			screen.bindings.Add(device.Action(screen.list.selected), binding)
+			screen.waiting = false
+			screen.save()
+		}
+		return false
+	}
+
+	screen.list.update(screen.bindings, screen.backOption()+1)
+
+	if screen.bindings.IsJustPressed(device.ActionMenuBack) {
+		return true
+	}
+
+	selected := screen.list.selected
+	isAction := selected < int(device.ActionsCount)
+
+	if isAction && device.IsClearJustPressed() && !device.Action(selected).IsRequired() {
+		screen.bindings.Clear(device.Action(selected))
+		screen.save()
+	}
+
+	if screen.bindings.IsJustPressed(device.ActionConfirm) {
+		switch {
+		case isAction:
+			screen.waiting = true
+		case selected == screen.resetOption():
+			bindings, err := device.NewBindings(settings.DefaultControls())
+			if err != nil {
+				screen.err = err
+				return false
+			}
+			*screen.bindings = *bindings
+			screen.save()
+		case selected == screen.backOption():
+			screen.list.selected = 0
+			return true
+		}
+	}
+
+	return false
+}
+
+func (screen *controlsScreen) save() {
+	screen.settings.Controls = screen.bindings.Controls()
+	screen.err = screen.saveFunc()
+}
+
+func (screen *controlsScreen) options() []string {
+	options := make([]string, 0, screen.backOption()+1)
+	for action := device.Action(0); action < device.ActionsCount; action++ {
+		names := make([]string, 0, len(screen.bindings.Get(action)))
+		for _, binding := range screen.bindings.Get(action) {
+			names = append(names, binding.String())
+		}
+
+		boundTo := strings.Join(names, ", ")
+		if screen.waiting && int(action) == screen.list.selected {
+			boundTo = "press a key or button..."
+		}
+		options = append(options, fmt.Sprintf("%s: %s", action, boundTo))
+	}
+	return append(options, "Reset to defaults", "Back")
+}
+
+func (screen *controlsScreen) draw(m *Menu, dst *ebiten.Image) {
+	w := constants.Width / 2.0
+
+	m.DrawText(dst, "Controls", 3, w, 40)
+	screen.list.draw(m, dst, screen.options(), w, 120)
+
+	help := fmt.Sprintf("%s to add a binding, BACKSPACE to clear, %s to go back",
+		screen.bindings.Describe(device.ActionConfirm), screen.bindings.Describe(device.ActionMenuBack))
+	if screen.err != nil {
+		help = fmt.Sprintf("failed saving settings: %s", screen.err)
+	}
+	m.DrawText(dst, help, 7, w, constants.Height-50)
+}
@@ -7,55 +7,136 @@ import (
 	"github.com/abelroes/gmtk2024/assets"
 	"github.com/abelroes/gmtk2024/assets/levels"
 	"github.com/abelroes/gmtk2024/src/audio"
-	"github.com/abelroes/gmtk2024/src/collision"
 	"github.com/abelroes/gmtk2024/src/entity"
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/abelroes/gmtk2024/src/replay"
+	"github.com/abelroes/gmtk2024/src/save"
 	"github.com/abelroes/gmtk2024/src/settings"
+	"github.com/abelroes/gmtk2024/src/simulation"
+	"github.com/abelroes/gmtk2024/src/storage"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type Engine struct {
+	sim          *simulation.Simulation
 	player       entity.Player
-	enemies      []entity.Wall
-	goal         entity.Goal
+	walls        *entity.Wall
+	goal         *entity.Goal
 	camera       entity.Camera
 	ui           *entity.Ui
 	audioManager *audio.Manager
 	asset        *assets.Asset
 	background   *entity.Background
 	settings     *settings.Settings
+	bindings     *device.Bindings
+	recorder     *replay.Recorder
+	playback     *input.Replay
+	storage      storage.Storage
+	ghost        *entity.Ghost
+	ghosts       map[int]*entity.Ghost
+	speedrun     speedrun
+	save         *save.Data
+	// saveReadOnly keeps a save that failed loading from being overwritten
+	saveReadOnly bool
 	onGameWin    func()
+	levelWatcher *levelWatcher
+	// levelsErr is the last error reloading the watched levels
+	levelsErr error
 
 	currentLevelIndex int
 }
 
-func NewEngine(asset *assets.Asset, audioManager *audio.Manager, settings *settings.Settings) *Engine {
+// goalRadius is the size the black hole is drawn at, its collider is a bit smaller
+const goalRadius = 40
+
+// simulationConfig hands the simulation the sizes it needs from the sprites
+func simulationConfig(asset *assets.Asset) simulation.Config {
+	bounds := asset.GetImage(assets.PlayerImgIndex).Bounds()
+	return simulation.Config{
+		PlayerPolygon: asset.PlayerPolygon,
+		PlayerW:       float64(bounds.Dx()),
+		PlayerH:       float64(bounds.Dy()),
+		GoalRadius:    goalRadius,
+	}
+}
+
+func NewEngine(asset *assets.Asset, audioManager *audio.Manager, settings *settings.Settings, bindings *device.Bindings, storage storage.Storage) *Engine {
 	gameEngine := &Engine{
-		enemies:      []entity.Wall{},
+		sim:          simulation.New(simulationConfig(asset)),
+		walls:        entity.NewWall(asset.GetImage(assets.EnemyImgIndex)),
+		goal:         entity.NewGoal(asset.GetImage(assets.GoalImgIndex), &settings.Debug),
 		audioManager: audioManager,
 		asset:        asset,
-		goal:         entity.NewGoal(asset.GetImage(assets.GoalImgIndex), &settings.Debug, 40),
 		ui:           entity.NewUi(asset.Font),
 		background:   entity.NewBackground(asset.Backgrounds),
 
 		currentLevelIndex: settings.Debug.InitialLevel,
 		settings:          settings,
+		bindings:          bindings,
+		storage:           storage,
+		ghosts:            map[int]*entity.Ghost{},
 	}
-	gameEngine.player = entity.NewPlayer(asset.GetImage(assets.PlayerImgIndex), &settings.Debug, asset.PlayerPolygon, gameEngine.handlePlayerEvents)
-
-	gameEngine.resetLevel()
+	gameEngine.player = entity.NewPlayer(asset.GetImage(assets.PlayerImgIndex), gameEngine.sim.Clock(), &settings.Debug)
+	gameEngine.sim.SetOnPlayerEvent(gameEngine.handlePlayerEvents)
+	gameEngine.recorder = replay.NewRecorder(input.Merge(device.NewKeyboard(bindings), device.NewGamepad(bindings)))
+	gameEngine.sim.SetInput(gameEngine.recorder)
+	gameEngine.save = gameEngine.loadSave()
+	gameEngine.speedrun.bests = &gameEngine.save.Bests
+
+	gameEngine.startRun(settings.Debug.InitialLevel, false)
 	return gameEngine
 }
 
+/*
+StartRun restarts the game at levelIndex and resets the speedrun timer. In
+individual level mode the level is repeated instead of moving on
+*/
+func (g *Engine) StartRun(levelIndex int, individualLevel bool) {
+	g.save.Stats.Attempts++
+	g.startRun(levelIndex, individualLevel)
+}
+
+func (g *Engine) startRun(levelIndex int, individualLevel bool) {
+	g.stopPlayback()
+	g.ui.ShowRestartText = false
+	g.currentLevelIndex = levelIndex
+	g.speedrun.start(levelIndex, individualLevel)
+	g.resetLevel()
+}
+
 func (e *Engine) SetOnGameWin(onGameWin func()) {
 	e.onGameWin = onGameWin
 }
 
 func (g *Engine) win() {
 	g.audioManager.PlaySoundFx(audio.WinFx)
+	g.saveAttempt()
+	g.updateGhost()
 
-	if g.currentLevelIndex == len(g.asset.Levels)-1 {
+	// the replay beat the level, not the player: nothing is unlocked and the player takes over at the same level
+	if g.playback != nil {
+		g.stopPlayback()
+		g.resetLevel()
+		return
+	}
+
+	g.completeLevel(g.currentLevelIndex)
+
+	lastLevel := g.currentLevelIndex == len(g.asset.Levels)-1
+	g.speedrun.completeLevel(g.currentLevelIndex, lastLevel)
+	if lastLevel && !g.speedrun.individualLevel {
+		g.save.Stats.GamesFinished++
+	}
+	g.writeSave()
+
+	if g.speedrun.individualLevel {
+		g.resetLevel()
+		return
+	}
+
+	if lastLevel {
 		g.onGameWin()
 		g.currentLevelIndex = 0
 		g.resetLevel()
@@ -71,28 +152,44 @@ func (e *Engine) goToNextLevel() {
 }
 
 func (g *Engine) drawPlayer(screen *ebiten.Image) {
-	g.player.Draw(screen, g.camera)
+	if g.ghost != nil {
+		g.ghost.Draw(screen, g.sim.Result().Ticks-1, g.camera)
+	}
+	g.player.Draw(screen, g.sim.Player(), g.camera)
 }
 
 func (g *Engine) drawEnemies(screen *ebiten.Image) {
-	for _, enemy := range g.enemies {
-		enemy.Draw(screen)
+	obstacles := g.sim.Obstacles()
+	for i := range obstacles {
+		entity.DrawObstacle(screen, &obstacles[i])
+	}
+	walls := g.sim.Walls()
+	for i := range walls {
+		g.walls.Draw(screen, &walls[i])
 	}
 }
 
-func (g *Engine) handlePlayerEvents(event entity.PlayerEvent) {
+func (g *Engine) handlePlayerEvents(event simulation.PlayerEvent) {
+	g.saveAttempt()
+	g.recordDeath(event)
+
 	switch event {
 
-	case entity.PlayerDiedByShrinking:
+	case simulation.PlayerDiedByShrinking:
 		g.audioManager.PlaySoundFx(audio.PopFx)
-		g.ui.ShowRestartText = true
+		g.showRestartText()
 
-	case entity.PlayerDiedByCollision, entity.PlayerDiedByOutOfBounds:
+	case simulation.PlayerDiedByCollision, simulation.PlayerDiedByOutOfBounds:
 		g.audioManager.PlaySoundFx(audio.ExplosionFx)
-		g.ui.ShowRestartText = true
+		g.showRestartText()
 	}
 }
 
+func (g *Engine) showRestartText() {
+	g.ui.RestartKeys = g.bindings.Describe(device.ActionRestart)
+	g.ui.ShowRestartText = true
+}
+
 func (g *Engine) drawBg(screen *ebiten.Image) {
 	g.background.Draw(screen)
 }
@@ -102,12 +199,33 @@ func (g *Engine) Draw(screen *ebiten.Image) {
 	g.drawBg(screen)
 	g.drawPlayer(screen)
 	g.drawEnemies(screen)
-	g.goal.Draw(screen)
+	g.goal.Draw(screen, g.sim.Goal())
 	g.ui.Draw(screen)
 
+	if g.settings.Speedrun.ShowTimer {
+		g.ui.DrawSplits(screen, g.speedrun.overlay(g.currentLevelIndex))
+	}
+
 	if g.settings.Debug.Fps {
 		ebitenutil.DebugPrint(screen, fmt.Sprintf("Fps: %.2f Tps: %.2f\ncurrentLevel: %d", ebiten.ActualFPS(), ebiten.ActualTPS(), g.currentLevelIndex+1))
 	}
+
+	if g.levelsErr != nil {
+		g.ui.DrawError(screen, fmt.Sprintf("failed reloading levels: %s", g.levelsErr))
+	}
+}
+
+func (g *Engine) IsPlayerDead() bool {
+	return g.sim.Player().Dead
+}
+
+// RestartLevel starts a new attempt at the current level
+func (g *Engine) RestartLevel() {
+	g.save.Stats.Attempts++
+	g.ui.ShowRestartText = false
+	g.stopPlayback()
+	g.speedrun.restartLevel()
+	g.resetLevel()
 }
 
 func (g *Engine) resetLevel() {
@@ -115,59 +233,27 @@ func (g *Engine) resetLevel() {
 }
 
 func (g *Engine) setLevel(level levels.Level) {
-	g.player.Reset()
-	g.player.Pos = level.PlayerStartPos
-	g.goal.SetPos(level.GoalPos)
-
-	walls := make([]entity.Wall, 0, len(level.Walls))
-
-	for _, wallInfo := range level.Walls {
-		var movement *entity.WallMovement
-		if wallInfo.Movement != nil {
-			movement = &entity.WallMovement{
-				Direction: wallInfo.Movement.Direction,
-				Speed:     wallInfo.Movement.Speed,
-				Cooldown:  wallInfo.Movement.Cooldown,
-			}
-		}
-
-		wall := entity.NewWall(g.asset.GetImage(assets.EnemyImgIndex), wallInfo.Pos.X, wallInfo.Pos.Y, wallInfo.W, wallInfo.H, movement)
-		walls = append(walls, wall)
-	}
-
-	g.enemies = walls
+	g.sim.SetLevel(level)
+	g.recorder.Reset()
+	g.ghost = g.loadGhost()
 }
 
 func (g *Engine) Update() error {
-	if g.player.Dead && (inpututil.IsKeyJustPressed(ebiten.KeyR) || inpututil.IsKeyJustPressed(ebiten.KeyEnter)) {
-		g.ui.ShowRestartText = false
-		g.resetLevel()
-	}
+	g.reloadLevels()
 
-	//Note: could gain perfomance by only updating moving walls
-	for i := range g.enemies {
-		g.enemies[i].Update()
+	if g.IsPlayerDead() && g.bindings.IsJustPressed(device.ActionRestart) {
+		g.RestartLevel()
 	}
 
 	g.audioManager.PlaySoundTrackInLoop()
 
-	g.player.Update()
-	g.goal.Update()
-	g.collisionDetection()
+	g.speedrun.tick()
+	g.save.Stats.PlayTime++
+	g.sim.Step()
+	g.player.Update(g.sim.Player())
+	if g.sim.Result().Won {
+		g.win()
+	}
 
 	return nil
 }
-
-func (g *Engine) collisionDetection() {
-	if !g.player.Dead {
-		for _, wall := range g.enemies {
-			if collision.HasCollidedRectPolygon(wall.Collisor, g.player.Collisor) {
-				g.player.DieByCollision()
-			}
-		}
-
-		if collision.HasCollidedRectPolygon(g.goal.Collider, g.player.Collisor) {
-			g.win()
-		}
-	}
-}
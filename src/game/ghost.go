@@ -0,0 +1,103 @@
+package game
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/abelroes/gmtk2024/assets"
+	"github.com/abelroes/gmtk2024/src/entity"
+	"github.com/abelroes/gmtk2024/src/simulation"
+	"github.com/abelroes/gmtk2024/src/storage"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+// every ghost frame is stored as x, y, rotation and scale as float32
+const ghostFrameSize = 4 * 4
+
+func ghostKey(levelIndex int) string {
+	return fmt.Sprintf("ghost-lvl%d", levelIndex+1)
+}
+
+func encodeGhost(frames []simulation.GhostFrame) []byte {
+	data := make([]byte, 0, len(frames)*ghostFrameSize)
+	for _, frame := range frames {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(frame.Pos.X)))
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(frame.Pos.Y)))
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(frame.Rot)))
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(frame.Scale)))
+	}
+	return data
+}
+
+func decodeGhost(data []byte) ([]simulation.GhostFrame, error) {
+	if len(data)%ghostFrameSize != 0 {
+		return nil, errors.New("corrupted ghost data")
+	}
+
+	next := func() float64 {
+		value := math.Float32frombits(binary.LittleEndian.Uint32(data))
+		data = data[4:]
+		return float64(value)
+	}
+
+	frames := make([]simulation.GhostFrame, len(data)/ghostFrameSize)
+	for i := range frames {
+		frames[i].Pos = vector.New(next(), next())
+		frames[i].Rot = next()
+		frames[i].Scale = next()
+	}
+	return frames, nil
+}
+
+// loadGhost returns the best run of the current level, or nil when it was never beaten
+func (g *Engine) loadGhost() *entity.Ghost {
+	if ghost, found := g.ghosts[g.currentLevelIndex]; found {
+		return ghost
+	}
+
+	var ghost *entity.Ghost
+	if g.storage != nil {
+		data, err := g.storage.Load(ghostKey(g.currentLevelIndex))
+		if err == nil {
+			var frames []simulation.GhostFrame
+			frames, err = decodeGhost(data)
+			if err == nil {
+				ghost = entity.NewGhost(g.asset.GetImage(assets.PlayerImgIndex), frames)
+			}
+		}
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("failed loading ghost: %s", err)
+		}
+	}
+
+	g.ghosts[g.currentLevelIndex] = ghost
+	return ghost
+}
+
+// updateGhost keeps the attempt that just reached the goal when it beats the stored one
+func (g *Engine) updateGhost() {
+	// a replay played back is a run that was already recorded, not a new one
+	if g.playback != nil {
+		return
+	}
+
+	trajectory := g.sim.Trajectory()
+
+	best := g.loadGhost()
+	if best != nil && len(best.Frames) <= len(trajectory) {
+		return
+	}
+
+	frames := make([]simulation.GhostFrame, len(trajectory))
+	copy(frames, trajectory)
+	g.ghosts[g.currentLevelIndex] = entity.NewGhost(g.asset.GetImage(assets.PlayerImgIndex), frames)
+
+	if g.storage != nil {
+		if err := g.storage.Save(ghostKey(g.currentLevelIndex), encodeGhost(frames)); err != nil {
+			log.Printf("failed saving ghost: %s", err)
+		}
+	}
+}
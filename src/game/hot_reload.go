@@ -0,0 +1,113 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/abelroes/gmtk2024/assets"
+	"github.com/abelroes/gmtk2024/src/clock"
+)
+
+const (
+	// campaignLevelsPath is where the embedded levels live in the source tree, relative to the repository root
+	campaignLevelsPath = "assets/levels/levels.tmx"
+	levelsPollInterval = clock.TicksPerSecond / 2
+)
+
+/*
+levelWatcher polls the modification time of a level file, directory or zip,
+Tiled writes the whole file on save so there is no need for anything finer
+*/
+type levelWatcher struct {
+	path    string
+	modTime time.Time
+	ticks   int
+}
+
+func newLevelWatcher(path string) (*levelWatcher, error) {
+	modTime, err := latestModTime(path)
+	if err != nil {
+		return nil, err
+	}
+	return &levelWatcher{path: path, modTime: modTime}, nil
+}
+
+// latestModTime is the modification time of path, or of the most recently modified file directly inside it
+func latestModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	latest := info.ModTime()
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, entry := range entries {
+			info, err := os.Stat(filepath.Join(path, entry.Name()))
+			if err == nil && info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+
+	return latest, nil
+}
+
+// changed reports whether the levels were modified since the last time it returned true
+func (watcher *levelWatcher) changed() bool {
+	watcher.ticks++
+	if watcher.ticks < levelsPollInterval {
+		return false
+	}
+	watcher.ticks = 0
+
+	modTime, err := latestModTime(watcher.path)
+	if err != nil || modTime.Equal(watcher.modTime) {
+		return false
+	}
+	watcher.modTime = modTime
+	return true
+}
+
+/*
+WatchLevels reloads the levels from path whenever it changes on disk and
+swaps the current level for its new version, so levels can be edited in
+Tiled while playing them
+*/
+func (g *Engine) WatchLevels(path string) error {
+	watcher, err := newLevelWatcher(path)
+	if err != nil {
+		return err
+	}
+	g.levelWatcher = watcher
+	return nil
+}
+
+// reloadLevels keeps the levels loaded when parsing fails, showing the error until the file is fixed
+func (g *Engine) reloadLevels() {
+	if g.levelWatcher == nil || !g.levelWatcher.changed() {
+		return
+	}
+
+	pack, err := assets.LoadLevelPack(g.levelWatcher.path)
+	g.levelsErr = err
+	if err != nil {
+		return
+	}
+
+	// ghosts of edited levels raced a layout that is gone, a nil entry keeps the stored one from being loaded again
+	for i, level := range pack.Levels {
+		if i >= len(g.asset.Levels) || !reflect.DeepEqual(level, g.asset.Levels[i]) {
+			g.ghosts[i] = nil
+		}
+	}
+
+	g.asset.Levels = pack.Levels
+	// the times of the run so far were set on the old levels
+	g.startRun(min(g.currentLevelIndex, len(g.asset.Levels)-1), g.speedrun.individualLevel)
+}
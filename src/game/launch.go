@@ -0,0 +1,118 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/abelroes/gmtk2024/assets"
+	"github.com/abelroes/gmtk2024/assets/levels"
+	"github.com/abelroes/gmtk2024/src/settings"
+)
+
+// LaunchOptions come from the command line, they override the settings file for the current session only
+type LaunchOptions struct {
+	// SettingsPath replaces settings.json when not empty
+	SettingsPath string
+	// Levels is a TMX file, a directory or a zip of them played instead of the embedded campaign
+	Levels string
+	// Level is either a level number, starting at 1, or the name of its Tiled object group
+	Level      string
+	SkipMenu   bool
+	Windowed   bool
+	Fullscreen bool
+	// Replay is played back as soon as the game starts
+	Replay string
+	// Record is overwritten by every level attempt
+	Record string
+}
+
+func (opts LaunchOptions) settingsPath() string {
+	if opts.SettingsPath != "" {
+		return opts.SettingsPath
+	}
+	return settingsPath
+}
+
+// levelPack loads the pack given with --levels, it is nil when the embedded campaign is played
+func (opts LaunchOptions) levelPack() (*assets.LevelPack, error) {
+	if opts.Levels == "" {
+		return nil, nil
+	}
+	return assets.LoadLevelPack(opts.Levels)
+}
+
+func findLevel(lvls []levels.Level, nameOrNumber string) (int, error) {
+	for i, lvl := range lvls {
+		if lvl.Name == nameOrNumber {
+			return i, nil
+		}
+	}
+
+	number, err := strconv.Atoi(nameOrNumber)
+	if err != nil || number < 1 || number > len(lvls) {
+		return 0, fmt.Errorf("level %q not found, expected a name or a number from 1 to %d", nameOrNumber, len(lvls))
+	}
+	return number - 1, nil
+}
+
+// override applies the options on top of the settings read from the file
+func (opts LaunchOptions) override(s *settings.Settings, lvls []levels.Level) error {
+	if opts.Windowed && opts.Fullscreen {
+		return errors.New("--windowed and --fullscreen cannot be used together")
+	}
+
+	if opts.Level != "" {
+		levelIndex, err := findLevel(lvls, opts.Level)
+		if err != nil {
+			return err
+		}
+		s.Debug.InitialLevel = levelIndex
+	}
+
+	if opts.SkipMenu {
+		s.Debug.SkipMenu = true
+	}
+	if opts.Windowed || opts.Fullscreen {
+		s.Screen.Fullscreen = opts.Fullscreen
+	}
+	if opts.Replay != "" {
+		s.Replay.Playback = opts.Replay
+	}
+	if opts.Record != "" {
+		s.Replay.Record = opts.Record
+	}
+	return nil
+}
+
+/*
+persisted returns current as it should be written back to the file: every
+value still equal to its override goes back to what was loaded, while values
+changed from the options screen since launch are kept
+*/
+func (opts LaunchOptions) persisted(current, loaded *settings.Settings) *settings.Settings {
+	s := current.Clone()
+
+	if opts.Level != "" {
+		s.Debug.InitialLevel = loaded.Debug.InitialLevel
+	}
+	if opts.SkipMenu {
+		restore(&s.Debug.SkipMenu, true, loaded.Debug.SkipMenu)
+	}
+	if opts.Windowed || opts.Fullscreen {
+		restore(&s.Screen.Fullscreen, opts.Fullscreen, loaded.Screen.Fullscreen)
+	}
+	if opts.Replay != "" {
+		restore(&s.Replay.Playback, opts.Replay, loaded.Replay.Playback)
+	}
+	if opts.Record != "" {
+		restore(&s.Replay.Record, opts.Record, loaded.Replay.Record)
+	}
+	return s
+}
+
+func restore[T comparable](value *T, overridden, loaded T) {
+	if *value == overridden {
+		*value = loaded
+	}
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/entity"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// levelSelectScreen lists every level with its status, the first entry toggles individual level mode
+type levelSelectScreen struct {
+	gameEngine      *Engine
+	bindings        *device.Bindings
+	list            optionList
+	individualLevel bool
+}
+
+func newLevelSelectScreen(gameEngine *Engine, bindings *device.Bindings) *levelSelectScreen {
+	return &levelSelectScreen{
+		gameEngine: gameEngine,
+		bindings:   bindings,
+	}
+}
+
+func (screen *levelSelectScreen) levelsCount() int {
+	return len(screen.gameEngine.asset.Levels)
+}
+
+func (screen *levelSelectScreen) backOption() int {
+	return screen.levelsCount() + 1
+}
+
+// update returns started when a level was picked and back when the player left the screen
+func (screen *levelSelectScreen) update() (started, back bool) {
+	screen.list.update(screen.bindings, screen.backOption()+1)
+
+	if screen.bindings.IsJustPressed(device.ActionMenuBack) {
+		return false, true
+	}
+
+	selected := screen.list.selected
+	confirmed := screen.bindings.IsJustPressed(device.ActionConfirm)
+
+	switch {
+	case selected == 0:
+		if confirmed || screen.bindings.IsJustPressed(device.ActionMenuLeft) || screen.bindings.IsJustPressed(device.ActionMenuRight) {
+			screen.individualLevel = !screen.individualLevel
+		}
+	case selected == screen.backOption():
+		return false, confirmed
+	case confirmed:
+		levelIndex := selected - 1
+		if screen.gameEngine.IsLevelUnlocked(levelIndex) {
+			screen.gameEngine.StartRun(levelIndex, screen.individualLevel)
+			return true, false
+		}
+	}
+
+	return false, false
+}
+
+func (screen *levelSelectScreen) options() []string {
+	mode := "Full run"
+	if screen.individualLevel {
+		mode = "Individual level"
+	}
+
+	options := make([]string, 0, screen.backOption()+1)
+	options = append(options, fmt.Sprintf("Mode: < %s >", mode))
+
+	for i, level := range screen.gameEngine.asset.Levels {
+		status := "locked"
+		switch {
+		case screen.gameEngine.IsLevelCompleted(i):
+			status = "completed"
+		case screen.gameEngine.IsLevelUnlocked(i):
+			status = "unlocked"
+		}
+
+		option := fmt.Sprintf("%s - %s", level.Name, status)
+		if best, found := screen.gameEngine.BestTime(i); found {
+			option += " - best " + entity.FormatDuration(best.Duration())
+		}
+		options = append(options, option)
+	}
+
+	return append(options, "Back")
+}
+
+func (screen *levelSelectScreen) draw(m *Menu, dst *ebiten.Image) {
+	w := constants.Width / 2.0
+
+	stats := screen.gameEngine.Stats()
+	m.DrawText(dst, "Level select", 3, w, 40)
+	m.DrawText(dst, fmt.Sprintf("Attempts: %d - Deaths: %d - Games finished: %d - Play time: %s",
+		stats.Attempts, stats.Deaths(), stats.GamesFinished, entity.FormatDuration(stats.PlayTime.Duration())), 7, w, 85)
+	screen.list.draw(m, dst, screen.options(), w, 120)
+}
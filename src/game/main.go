@@ -1,37 +1,115 @@
 package game
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"os"
 	"runtime"
+	"strings"
+	"unicode"
 
 	"github.com/abelroes/gmtk2024/assets"
 	"github.com/abelroes/gmtk2024/src/audio"
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/abelroes/gmtk2024/src/replay"
 	"github.com/abelroes/gmtk2024/src/settings"
+	"github.com/abelroes/gmtk2024/src/storage"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-func loadSettings() (*settings.Settings, error) {
+const settingsPath = "settings.json"
+
+// settingsFile reads and writes the settings, keeping the launch options out of the file
+type settingsFile struct {
+	store  storage.Storage
+	path   string
+	launch LaunchOptions
+	// loaded holds the settings as they were read, before the launch options overrode them
+	loaded *settings.Settings
+}
+
+/*
+load reads the settings file, from the working directory on desktop and
+from the browser storage on wasm. On first run a commented default file is
+written so there is something to edit
+*/
+func (file *settingsFile) load() (*settings.Settings, error) {
+	var (
+		data []byte
+		err  error
+	)
 	if runtime.GOOS == "js" {
-		return settings.DefaultSettings, nil
+		if file.store == nil {
+			file.loaded = settings.DefaultSettings.Clone()
+			return file.loaded.Clone(), nil
+		}
+		data, err = file.store.Load(file.path)
+	} else {
+		data, err = os.ReadFile(file.path)
 	}
 
-	f, err := os.Open("settings.json")
-	if os.IsNotExist(err) {
-		return settings.DefaultSettings, nil
+	switch {
+	case errors.Is(err, storage.ErrNotFound) || os.IsNotExist(err):
+		file.loaded = settings.DefaultSettings.Clone()
+		if err := file.write(file.loaded); err != nil {
+			log.Printf("failed writing default settings: %s", err)
+		}
+	case err != nil:
+		return nil, err
+	default:
+		file.loaded, err = settings.Parse(data)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	return file.loaded.Clone(), nil
+}
+
+// save writes s without the values that only come from the launch options
+func (file *settingsFile) save(s *settings.Settings) error {
+	return file.write(file.launch.persisted(s, file.loaded))
+}
+
+func (file *settingsFile) write(s *settings.Settings) error {
+	data, err := settings.Marshal(s)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	settings := &settings.Settings{}
-	err = json.NewDecoder(f).Decode(settings)
-	return settings, err
+	if runtime.GOOS == "js" {
+		if file.store == nil {
+			return nil
+		}
+		return file.store.Save(file.path, data)
+	}
+
+	return os.WriteFile(file.path, data, 0o644)
+}
+
+// packStoragePrefix turns a pack title into a prefix safe to use in file names
+func packStoragePrefix(title string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, title)
+	return "pack-" + slug + "-"
 }
 
-func Main() error {
-	settings, err := loadSettings()
+func Main(launch LaunchOptions) error {
+	// progress is simply not kept between sessions when there is nowhere to store it
+	store, err := storage.New()
+	if err != nil {
+		log.Printf("persistent storage unavailable: %s", err)
+	}
+
+	file := &settingsFile{store: store, path: launch.settingsPath(), launch: launch}
+	settings, err := file.load()
 	if err != nil {
 		return err
 	}
@@ -41,17 +119,70 @@ func Main() error {
 		return err
 	}
 
+	title := gameName
+	// a pack keeps its own progress, bests and ghosts so it never overwrites the campaign's
+	gameStore := store
+	pack, err := launch.levelPack()
+	if err != nil {
+		return err
+	}
+	if pack != nil {
+		assets.UseLevelPack(pack)
+		title = fmt.Sprintf("%s - %s", gameName, pack.Title)
+		gameStore = storage.WithPrefix(store, packStoragePrefix(pack.Title))
+	}
+
+	if err := launch.override(settings, assets.Levels); err != nil {
+		return err
+	}
+
+	if err := settings.Validate(len(assets.Levels)); err != nil {
+		return err
+	}
+
 	audioManager, err := audio.NewManager(assets.Sounds, settings.Volume)
 	if err != nil {
 		return err
 	}
 
-	gameEngine := NewEngine(assets, audioManager, settings)
-	menu := NewMenu(assets, audioManager, gameEngine, settings, assets.Credits)
+	bindings, err := device.NewBindings(settings.Controls)
+	if err != nil {
+		return err
+	}
+
+	gameEngine := NewEngine(assets, audioManager, settings, bindings, gameStore)
+	saveSettings := func() error {
+		return file.save(settings)
+	}
+	menu := NewMenu(assets, audioManager, gameEngine, settings, bindings, saveSettings, assets.Credits)
 	gameEngine.SetOnGameWin(menu.OnGameWin)
 
+	if constants.DevBuild && runtime.GOOS != "js" {
+		levelsPath := campaignLevelsPath
+		if launch.Levels != "" {
+			levelsPath = launch.Levels
+		}
+		if err := gameEngine.WatchLevels(levelsPath); err != nil {
+			log.Printf("levels are not reloaded when edited: %s", err)
+		}
+	}
+
+	if settings.Replay.Playback != "" {
+		r, err := replay.Load(settings.Replay.Playback)
+		if err != nil {
+			return err
+		}
+
+		if err := gameEngine.PlayReplay(r); err != nil {
+			return err
+		}
+		menu.Play()
+	}
+
 	ebiten.SetWindowSize(settings.Screen.Width, settings.Screen.Height)
-	ebiten.SetWindowTitle(gameName)
+	ebiten.SetFullscreen(settings.Screen.Fullscreen)
+	ebiten.SetWindowTitle(title)
+	ebiten.SetTPS(clock.TicksPerSecond)
 
 	return ebiten.RunGame(menu)
 }
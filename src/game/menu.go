@@ -1,6 +1,7 @@
 package game
 
 import (
+	"fmt"
 	"image/color"
 	"time"
 
@@ -8,9 +9,9 @@ import (
 	"github.com/abelroes/gmtk2024/src/audio"
 	"github.com/abelroes/gmtk2024/src/constants"
 	"github.com/abelroes/gmtk2024/src/entity"
+	"github.com/abelroes/gmtk2024/src/input/device"
 	"github.com/abelroes/gmtk2024/src/settings"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
@@ -27,8 +28,24 @@ const (
 	OnMenuState MenuState = iota
 	PlayingState
 	CreditsState
+	ControlsState
+	PausedState
+	LevelSelectState
+	OptionsState
 )
 
+const (
+	startOption = iota
+	levelSelectOption
+	optionsOption
+)
+
+var mainMenuOptions = []string{
+	startOption:       "Start",
+	levelSelectOption: "Level select",
+	optionsOption:     "Options",
+}
+
 type Menu struct {
 	gameEngine             *Engine
 	menuTransitionHappened bool
@@ -36,13 +53,20 @@ type Menu struct {
 	font                   *text.GoTextFaceSource
 	background             *entity.Background
 	settings               *settings.Settings
+	bindings               *device.Bindings
+	mainMenu               optionList
+	levelSelect            *levelSelectScreen
+	options                *optionsScreen
+	controls               *controlsScreen
+	pauseMenu              optionList
+	settingsReturnState    MenuState
 	state                  MenuState
 	credits                string
 	creditsStartedAt       time.Time
 	creditsY               float64
 }
 
-func NewMenu(assets *assets.Asset, audioManager *audio.Manager, gameEngine *Engine, settings *settings.Settings, credits string) *Menu {
+func NewMenu(assets *assets.Asset, audioManager *audio.Manager, gameEngine *Engine, settings *settings.Settings, bindings *device.Bindings, saveSettings func() error, credits string) *Menu {
 	initialState := OnMenuState
 	if settings.Debug.SkipMenu {
 		initialState = PlayingState
@@ -55,6 +79,10 @@ func NewMenu(assets *assets.Asset, audioManager *audio.Manager, gameEngine *Engi
 		background:   entity.NewBackground(assets.Backgrounds),
 		font:         assets.Font,
 		settings:     settings,
+		bindings:     bindings,
+		levelSelect:  newLevelSelectScreen(gameEngine, bindings),
+		options:      newOptionsScreen(settings, audioManager, saveSettings),
+		controls:     newControlsScreen(bindings, settings, saveSettings),
 		credits:      credits,
 	}
 	return menu
@@ -63,11 +91,55 @@ func NewMenu(assets *assets.Asset, audioManager *audio.Manager, gameEngine *Engi
 func (m *Menu) Update() error {
 	switch m.state {
 	case PlayingState:
+		// Start both pauses and restarts on a gamepad, after dying it restarts
+		restarting := m.gameEngine.IsPlayerDead() && m.bindings.IsJustPressed(device.ActionRestart)
+		if (m.bindings.IsJustPressed(device.ActionPause) && !restarting) || !ebiten.IsFocused() {
+			m.pause()
+			return nil
+		}
 		m.gameEngine.Update()
+	case PausedState:
+		m.updatePaused()
 	case OnMenuState:
 		m.audioManager.PlaySoundTrackInLoop()
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyKPEnter) {
+		m.mainMenu.update(m.bindings, len(mainMenuOptions))
+		if m.bindings.IsJustPressed(device.ActionConfirm) {
+			switch m.mainMenu.selected {
+			case startOption:
+				m.gameEngine.StartRun(m.settings.Debug.InitialLevel, false)
+				m.state = PlayingState
+			case levelSelectOption:
+				m.state = LevelSelectState
+			case optionsOption:
+				m.openSettings(OnMenuState)
+			}
+		}
+	case LevelSelectState:
+		m.audioManager.PlaySoundTrackInLoop()
+		started, back := m.levelSelect.update()
+		switch {
+		case started:
 			m.state = PlayingState
+		case back:
+			m.state = OnMenuState
+		}
+	case OptionsState:
+		if m.settingsReturnState != PausedState {
+			m.audioManager.PlaySoundTrackInLoop()
+		}
+		controls, back := m.options.update(m.bindings)
+		switch {
+		case controls:
+			m.state = ControlsState
+		case back:
+			m.state = m.settingsReturnState
+		}
+	case ControlsState:
+		if m.settingsReturnState != PausedState {
+			m.audioManager.PlaySoundTrackInLoop()
+		}
+		if m.controls.update() {
+			m.state = OptionsState
 		}
 	case CreditsState:
 		if m.creditsStartedAt == (time.Time{}) {
@@ -81,7 +153,7 @@ func (m *Menu) Update() error {
 		if time.Since(m.creditsStartedAt) > creditsRolStart && m.creditsY > maxCreditRoll {
 			m.creditsY -= creditRollSpeed
 		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if m.bindings.IsJustPressed(device.ActionConfirm) {
 			m.state = OnMenuState
 			m.creditsStartedAt = time.Time{}
 			m.creditsY = 0
@@ -91,6 +163,17 @@ func (m *Menu) Update() error {
 	return nil
 }
 
+// openSettings shows the options screen, going back to returnState once the player leaves it
+func (m *Menu) openSettings(returnState MenuState) {
+	m.settingsReturnState = returnState
+	m.state = OptionsState
+}
+
+// Play skips the main menu straight into the game
+func (m *Menu) Play() {
+	m.state = PlayingState
+}
+
 func (m *Menu) OnGameWin() {
 	m.state = CreditsState
 	m.audioManager.StopSoundTrack()
@@ -106,14 +189,30 @@ func (m *Menu) Draw(screen *ebiten.Image) {
 		}
 
 		m.gameEngine.Draw(screen)
+	case PausedState:
+		m.drawPaused(screen)
 	case OnMenuState:
 		m.background.Draw(screen)
 		w := constants.Width / 2.0
 		h := constants.Height / 2.0
 
 		m.DrawText(screen, gameName, 1, w, h-150)
-		m.DrawText(screen, "Use UP/W for propulsion and LEFT/A or RIGHT/D to steer", 7, w, h-50)
-		m.DrawText(screen, "Press ENTER to start", 7, w, h)
+		m.DrawText(screen, fmt.Sprintf("Use %s for propulsion", m.bindings.Describe(device.ActionThrust)), 7, w, h-70)
+		m.DrawText(screen, fmt.Sprintf("and %s or %s to steer", m.bindings.Describe(device.ActionSteerLeft), m.bindings.Describe(device.ActionSteerRight)), 7, w, h-50)
+		m.DrawText(screen, "the left stick of a gamepad steers too", 7, w, h-30)
+		m.mainMenu.draw(m, screen, mainMenuOptions, w, h)
+
+	case LevelSelectState:
+		m.background.Draw(screen)
+		m.levelSelect.draw(m, screen)
+
+	case OptionsState:
+		m.background.Draw(screen)
+		m.options.draw(m, screen)
+
+	case ControlsState:
+		m.background.Draw(screen)
+		m.controls.draw(m, screen)
 
 	case CreditsState:
 		m.background.Draw(screen)
@@ -121,7 +220,7 @@ func (m *Menu) Draw(screen *ebiten.Image) {
 		m.DrawText(screen, "Congratulations!", 1, w, m.creditsY)
 		m.DrawText(screen, "thanks for playing", 2, w, m.creditsY+50)
 		m.DrawText(screen, "Credits", 3, w, m.creditsY+120)
-		m.DrawText(screen, m.credits+"\nPress enter to go back to main menu", 6, w, m.creditsY+150)
+		m.DrawText(screen, m.credits+"\nPress enter or start to go back to main menu", 6, w, m.creditsY+150)
 	}
 }
 
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	optionSpacing     = 22.0
+	maxVisibleOptions = 14
+)
+
+// optionList is a vertical list of menu entries navigated with up/down
+type optionList struct {
+	selected int
+}
+
+func (list *optionList) update(bindings *device.Bindings, count int) {
+	if count == 0 {
+		return
+	}
+
+	if bindings.IsJustPressed(device.ActionMenuUp) {
+		list.selected = (list.selected + count - 1) % count
+	}
+	if bindings.IsJustPressed(device.ActionMenuDown) {
+		list.selected = (list.selected + 1) % count
+	}
+}
+
+// draw scrolls the list when it has more than maxVisibleOptions entries so the selected one is always shown
+func (list *optionList) draw(m *Menu, screen *ebiten.Image, options []string, x, y float64) {
+	first := 0
+	if len(options) > maxVisibleOptions {
+		first = min(max(list.selected-maxVisibleOptions/2, 0), len(options)-maxVisibleOptions)
+	}
+	last := min(first+maxVisibleOptions, len(options))
+
+	for i := first; i < last; i++ {
+		option := options[i]
+		if i == list.selected {
+			option = "> " + option + " <"
+		}
+		m.DrawText(screen, option, 7, x, y+float64(i-first)*optionSpacing)
+	}
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/audio"
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/abelroes/gmtk2024/src/settings"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const volumeStep = .05
+
+var resolutionPresets = []settings.SettingsScreen{
+	{Width: constants.Width, Height: constants.Height},
+	{Width: constants.Width * 1.5, Height: constants.Height * 1.5},
+	{Width: constants.Width * 2, Height: constants.Height * 2},
+	{Width: constants.Width * 2.5, Height: constants.Height * 2.5},
+	{Width: constants.Width * 3, Height: constants.Height * 3},
+}
+
+// optionsEntry is a line of the options screen, change is called with -1/+1 on left/right and 0 on confirm
+type optionsEntry struct {
+	label  func() string
+	change func(direction int)
+	// opensControls entries open the controls screen on confirm instead of changing a setting
+	opensControls bool
+}
+
+/*
+optionsScreen edits settings.Settings, applying every change right away and
+writing the settings back once the player leaves it. The debug flags are only
+listed in developer builds (-tags dev)
+*/
+type optionsScreen struct {
+	settings     *settings.Settings
+	audioManager *audio.Manager
+	saveFunc     func() error
+	list         optionList
+	entries      []optionsEntry
+	dirty        bool
+	err          error
+}
+
+func newOptionsScreen(s *settings.Settings, audioManager *audio.Manager, saveFunc func() error) *optionsScreen {
+	screen := &optionsScreen{
+		settings:     s,
+		audioManager: audioManager,
+		saveFunc:     saveFunc,
+	}
+
+	screen.entries = []optionsEntry{
+		screen.volumeEntry("Soundtrack volume", &s.Volume.SoundTrack),
+		screen.volumeEntry("Effects volume", &s.Volume.SoundFx),
+		{
+			label: func() string {
+				return fmt.Sprintf("Resolution: < %dx%d >", s.Screen.Width, s.Screen.Height)
+			},
+			change: screen.changeResolution,
+		},
+		screen.toggleEntry("Fullscreen", &s.Screen.Fullscreen, func() {
+			ebiten.SetFullscreen(s.Screen.Fullscreen)
+		}),
+		screen.toggleEntry("Speedrun timer", &s.Speedrun.ShowTimer, nil),
+		{
+			label:         func() string { return "Controls" },
+			opensControls: true,
+		},
+	}
+
+	if constants.DevBuild {
+		screen.entries = append(screen.entries,
+			screen.toggleEntry("Debug: FPS", &s.Debug.Fps, nil),
+			screen.toggleEntry("Debug: player hitbox", &s.Debug.PlayerHitbox, nil),
+			screen.toggleEntry("Debug: goal hitbox", &s.Debug.GoalHitbox, nil),
+			screen.toggleEntry("Debug: skip menu", &s.Debug.SkipMenu, nil),
+		)
+	}
+
+	return screen
+}
+
+func (screen *optionsScreen) volumeEntry(name string, volume *float64) optionsEntry {
+	return optionsEntry{
+		label: func() string {
+			return fmt.Sprintf("%s: < %d%% >", name, int(math.Round(*volume*100)))
+		},
+		change: func(direction int) {
+			*volume = min(max(*volume+float64(direction)*volumeStep, 0), 1)
+			screen.audioManager.SetVolumes(screen.settings.Volume)
+		},
+	}
+}
+
+func (screen *optionsScreen) toggleEntry(name string, value *bool, apply func()) optionsEntry {
+	return optionsEntry{
+		label: func() string {
+			state := "off"
+			if *value {
+				state = "on"
+			}
+			return fmt.Sprintf("%s: < %s >", name, state)
+		},
+		change: func(int) {
+			*value = !*value
+			if apply != nil {
+				apply()
+			}
+		},
+	}
+}
+
+func (screen *optionsScreen) changeResolution(direction int) {
+	current := 0
+	for i, preset := range resolutionPresets {
+		if preset.Width == screen.settings.Screen.Width && preset.Height == screen.settings.Screen.Height {
+			current = i
+		}
+	}
+
+	if direction == 0 {
+		direction = 1
+	}
+	next := resolutionPresets[(current+len(resolutionPresets)+direction)%len(resolutionPresets)]
+
+	screen.settings.Screen.Width = next.Width
+	screen.settings.Screen.Height = next.Height
+	ebiten.SetWindowSize(next.Width, next.Height)
+}
+
+func (screen *optionsScreen) backOption() int {
+	return len(screen.entries)
+}
+
+// update reports whether the controls screen was picked or the player left the screen
+func (screen *optionsScreen) update(bindings *device.Bindings) (controls, back bool) {
+	screen.list.update(bindings, screen.backOption()+1)
+	selected := screen.list.selected
+	confirmed := bindings.IsJustPressed(device.ActionConfirm)
+
+	if bindings.IsJustPressed(device.ActionMenuBack) || (selected == screen.backOption() && confirmed) {
+		back = screen.save()
+		if back {
+			screen.list.selected = 0
+		}
+		return false, back
+	}
+
+	if selected == screen.backOption() {
+		return false, false
+	}
+
+	if screen.entries[selected].opensControls {
+		return confirmed, false
+	}
+
+	direction, changed := 0, true
+	switch {
+	case bindings.IsJustPressed(device.ActionMenuLeft):
+		direction = -1
+	case bindings.IsJustPressed(device.ActionMenuRight):
+		direction = 1
+	case confirmed:
+	default:
+		changed = false
+	}
+
+	if changed {
+		screen.entries[selected].change(direction)
+		screen.dirty = true
+		screen.err = nil
+	}
+
+	return false, false
+}
+
+/*
+save writes the settings if anything changed. When that fails the screen stays
+once to show the error, the next try leaves it with the changes kept for the
+session
+*/
+func (screen *optionsScreen) save() bool {
+	if screen.err != nil || !screen.dirty {
+		screen.err = nil
+		screen.dirty = false
+		return true
+	}
+
+	screen.err = screen.saveFunc()
+	screen.dirty = screen.err != nil
+	return screen.err == nil
+}
+
+func (screen *optionsScreen) draw(m *Menu, dst *ebiten.Image) {
+	w := constants.Width / 2.0
+
+	options := make([]string, 0, screen.backOption()+1)
+	for _, entry := range screen.entries {
+		options = append(options, entry.label())
+	}
+	options = append(options, "Back")
+
+	m.DrawText(dst, "Options", 3, w, 40)
+	screen.list.draw(m, dst, options, w, 120)
+
+	back := m.bindings.Describe(device.ActionMenuBack)
+	help := fmt.Sprintf("%s or %s to change, %s to save and go back",
+		m.bindings.Describe(device.ActionMenuLeft), m.bindings.Describe(device.ActionMenuRight), back)
+	if screen.err != nil {
+		help = fmt.Sprintf("failed saving settings: %s, %s to go back anyway", screen.err, back)
+	}
+	m.DrawText(dst, help, 7, w, constants.Height-50)
+}
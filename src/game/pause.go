@@ -0,0 +1,75 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input/device"
+	"github.com/hajimehoshi/ebiten/v2"
+	ebivector "github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	resumeOption = iota
+	restartLevelOption
+	pauseSettingsOption
+	quitOption
+)
+
+var pauseOptions = []string{
+	resumeOption:        "Resume",
+	restartLevelOption:  "Restart level",
+	pauseSettingsOption: "Settings",
+	quitOption:          "Quit to main menu",
+}
+
+/*
+pause freezes the game: the engine is not updated anymore, so the
+simulation clock, walls and particles stop where they are
+*/
+func (m *Menu) pause() {
+	m.state = PausedState
+	m.pauseMenu.selected = resumeOption
+	m.audioManager.PauseAll()
+}
+
+func (m *Menu) resume() {
+	m.state = PlayingState
+	m.audioManager.ResumeAll()
+}
+
+func (m *Menu) updatePaused() {
+	m.pauseMenu.update(m.bindings, len(pauseOptions))
+
+	if m.bindings.IsJustPressed(device.ActionMenuBack) || m.bindings.IsJustPressed(device.ActionPause) {
+		m.resume()
+		return
+	}
+
+	if !m.bindings.IsJustPressed(device.ActionConfirm) {
+		return
+	}
+
+	switch m.pauseMenu.selected {
+	case resumeOption:
+		m.resume()
+	case restartLevelOption:
+		m.gameEngine.RestartLevel()
+		m.resume()
+	case pauseSettingsOption:
+		m.openSettings(PausedState)
+	case quitOption:
+		m.audioManager.StopPaused()
+		m.state = OnMenuState
+	}
+}
+
+func (m *Menu) drawPaused(screen *ebiten.Image) {
+	m.gameEngine.Draw(screen)
+	ebivector.DrawFilledRect(screen, 0, 0, constants.Width, constants.Height, color.RGBA{A: 0xb0}, false)
+
+	w := constants.Width / 2.0
+	h := constants.Height / 2.0
+	m.DrawText(screen, "Paused", 3, w, h-100)
+	m.pauseMenu.draw(m, screen, pauseOptions, w, h-20)
+}
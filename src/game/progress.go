@@ -0,0 +1,82 @@
+package game
+
+import (
+	"errors"
+	"log"
+
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/save"
+	"github.com/abelroes/gmtk2024/src/simulation"
+)
+
+func (g *Engine) loadSave() *save.Data {
+	if g.storage == nil {
+		return save.New()
+	}
+
+	data, err := save.Load(g.storage)
+	if err != nil {
+		log.Printf("failed loading save: %s", err)
+	}
+	g.saveReadOnly = errors.Is(err, save.ErrNotWritable)
+	return data
+}
+
+func (g *Engine) writeSave() {
+	if g.storage == nil || g.saveReadOnly {
+		return
+	}
+
+	if err := g.save.Write(g.storage); err != nil {
+		log.Printf("failed writing save: %s", err)
+	}
+}
+
+func (g *Engine) recordDeath(event simulation.PlayerEvent) {
+	// deaths of a replay played back were counted when it was recorded
+	if g.playback != nil {
+		return
+	}
+
+	switch event {
+	case simulation.PlayerDiedByCollision:
+		g.save.Stats.DeathsByCollision++
+	case simulation.PlayerDiedByShrinking:
+		g.save.Stats.DeathsByShrinking++
+	case simulation.PlayerDiedByOutOfBounds:
+		g.save.Stats.DeathsByOutOfBounds++
+	}
+	g.writeSave()
+}
+
+func (g *Engine) completeLevel(levelIndex int) {
+	g.save.Stats.Wins++
+	g.save.Completed[levelIndex] = true
+}
+
+func (g *Engine) IsLevelCompleted(levelIndex int) bool {
+	return g.save.Completed[levelIndex]
+}
+
+func (g *Engine) IsLevelUnlocked(levelIndex int) bool {
+	return g.save.IsUnlocked(levelIndex)
+}
+
+func (g *Engine) Stats() save.Stats {
+	return g.save.Stats
+}
+
+// BestTime is the fastest the level was ever beaten, in a full run or in individual level mode
+func (g *Engine) BestTime(levelIndex int) (clock.Tick, bool) {
+	split, hasSplit := g.save.Bests.Splits[levelIndex]
+	individual, hasIndividual := g.save.Bests.IndividualLevels[levelIndex]
+
+	switch {
+	case hasSplit && hasIndividual:
+		return min(split, individual), true
+	case hasSplit:
+		return split, true
+	default:
+		return individual, hasIndividual
+	}
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/abelroes/gmtk2024/src/replay"
+)
+
+/*
+PlayReplay restarts the replay's level and feeds its inputs to the player
+instead of the keyboard/gamepad, until the attempt ends or the player restarts
+*/
+func (g *Engine) PlayReplay(r *replay.Replay) error {
+	if r.Header.Pack != g.packTitle() {
+		return fmt.Errorf("replay recorded on level pack %q, playing %q", r.Header.Pack, g.packTitle())
+	}
+	if r.Header.LevelIndex < 0 || r.Header.LevelIndex >= len(g.asset.Levels) {
+		return fmt.Errorf("replay level %d does not exist", r.Header.LevelIndex+1)
+	}
+
+	if r.Header.GameVersion != constants.GameVersion {
+		log.Printf("replay recorded with version %s, running %s, it may not play back exactly", r.Header.GameVersion, constants.GameVersion)
+	}
+	if r.Header.SimulationHash != g.sim.Config().Hash(g.asset.Levels[r.Header.LevelIndex]) {
+		log.Printf("replay recorded with a different level layout or ship, it may not play back exactly")
+	}
+
+	// watching a replay is not a run, it must not set splits or personal bests
+	g.speedrun.stop()
+	g.playback = input.NewReplay(r.Inputs)
+	g.sim.SetInput(g.playback)
+	g.ui.ShowReplayText = true
+	g.ui.ShowRestartText = false
+
+	g.currentLevelIndex = r.Header.LevelIndex
+	g.resetLevel()
+	return nil
+}
+
+// packTitle identifies the levels being played, empty for the embedded campaign
+func (g *Engine) packTitle() string {
+	if g.asset.LevelPack == nil {
+		return ""
+	}
+	return g.asset.LevelPack.Title
+}
+
+// stopPlayback hands the ship back to the player, timing a fresh individual level run of the replay's level
+func (g *Engine) stopPlayback() {
+	if g.playback == nil {
+		return
+	}
+
+	g.playback = nil
+	g.sim.SetInput(g.recorder)
+	g.ui.ShowReplayText = false
+	g.speedrun.start(g.currentLevelIndex, true)
+}
+
+// saveAttempt writes the attempt that just ended to settings.Replay.Dir and settings.Replay.Record
+func (g *Engine) saveAttempt() {
+	replaySettings := g.settings.Replay
+	if g.playback != nil || (replaySettings.Dir == "" && replaySettings.Record == "") || runtime.GOOS == "js" {
+		return
+	}
+
+	r := g.recorder.Replay(replay.Header{
+		Pack:           g.packTitle(),
+		LevelIndex:     g.currentLevelIndex,
+		GameVersion:    constants.GameVersion,
+		SimulationHash: g.sim.Config().Hash(g.asset.Levels[g.currentLevelIndex]),
+	})
+
+	if replaySettings.Dir != "" {
+		if _, err := replay.Save(replaySettings.Dir, r); err != nil {
+			log.Printf("failed saving replay: %s", err)
+		}
+	}
+
+	if replaySettings.Record != "" {
+		if err := replay.SaveFile(replaySettings.Record, r); err != nil {
+			log.Printf("failed saving replay: %s", err)
+		}
+	}
+}
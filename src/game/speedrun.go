@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/entity"
+	"github.com/abelroes/gmtk2024/src/save"
+)
+
+type split struct {
+	levelIndex int
+	ticks      clock.Tick
+}
+
+/*
+speedrun times the current run in simulation ticks. A run starts at a level
+and either goes through every following level (full game, only eligible for
+the full game best when started at lvl1) or repeats a single level
+(individual level mode)
+*/
+type speedrun struct {
+	individualLevel bool
+	startLevel      int
+	running         bool
+	runTicks        clock.Tick
+	levelTicks      clock.Tick
+	splits          []split
+	bests           *save.PersonalBests
+	// previous are the bests as they were when the run (or individual level attempt) started, used for deltas
+	previous save.PersonalBests
+}
+
+func (run *speedrun) start(levelIndex int, individualLevel bool) {
+	run.individualLevel = individualLevel
+	run.startLevel = levelIndex
+	run.running = true
+	run.runTicks = 0
+	run.levelTicks = 0
+	run.splits = run.splits[:0]
+	run.previous = run.bests.Clone()
+}
+
+// stop ends the run without recording anything, the splits stay on screen as they were
+func (run *speedrun) stop() {
+	run.running = false
+}
+
+func (run *speedrun) tick() {
+	if run.running {
+		run.runTicks++
+		run.levelTicks++
+	}
+}
+
+// restartLevel is called when the player restarts after dying, only individual level attempts start over
+func (run *speedrun) restartLevel() {
+	if run.individualLevel {
+		run.runTicks = 0
+		run.levelTicks = 0
+	}
+}
+
+// completeLevel records the split of the level that was just beaten, updating the personal bests it improved
+func (run *speedrun) completeLevel(levelIndex int, lastLevel bool) {
+	if !run.running {
+		return
+	}
+
+	ticks := run.levelTicks
+	run.levelTicks = 0
+
+	if run.individualLevel {
+		run.runTicks = 0
+		run.previous = run.bests.Clone()
+
+		best, found := run.bests.IndividualLevels[levelIndex]
+		if !found || ticks < best {
+			run.bests.IndividualLevels[levelIndex] = ticks
+		}
+		return
+	}
+
+	run.splits = append(run.splits, split{levelIndex: levelIndex, ticks: ticks})
+
+	best, found := run.bests.Splits[levelIndex]
+	if !found || ticks < best {
+		run.bests.Splits[levelIndex] = ticks
+	}
+
+	if lastLevel {
+		run.running = false
+		fullGame := run.startLevel == 0
+		if fullGame && (run.bests.FullGame == 0 || run.runTicks < run.bests.FullGame) {
+			run.bests.FullGame = run.runTicks
+		}
+	}
+}
+
+/*
+overlay builds the splits shown on screen, deltas compare against the
+personal bests as they were before this run improved them
+*/
+func (run *speedrun) overlay(currentLevel int) []entity.Split {
+	previous := run.previous
+
+	levelName := func(index int) string {
+		return fmt.Sprintf("Level %d", index+1)
+	}
+
+	if run.individualLevel {
+		best, found := previous.IndividualLevels[currentLevel]
+		return []entity.Split{
+			entity.NewSplit(levelName(currentLevel)+" (IL)", run.levelTicks.Duration(), best.Duration(), found),
+		}
+	}
+
+	splits := make([]entity.Split, 0, len(run.splits)+2)
+	for _, s := range run.splits {
+		best, found := previous.Splits[s.levelIndex]
+		splits = append(splits, entity.NewSplit(levelName(s.levelIndex), s.ticks.Duration(), best.Duration(), found))
+	}
+
+	if run.running {
+		best, found := previous.Splits[currentLevel]
+		splits = append(splits, entity.NewSplit(levelName(currentLevel), run.levelTicks.Duration(), best.Duration(), found))
+	}
+
+	hasFullGameBest := run.startLevel == 0 && previous.FullGame != 0
+	return append(splits, entity.NewSplit("Total", run.runTicks.Duration(), previous.FullGame.Duration(), hasFullGameBest))
+}
@@ -0,0 +1,279 @@
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abelroes/gmtk2024/src/settings"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type Action int
+
+const (
+	ActionSteerLeft Action = iota
+	ActionSteerRight
+	ActionThrust
+	ActionRestart
+	ActionConfirm
+	ActionPause
+	ActionMenuUp
+	ActionMenuDown
+	ActionMenuLeft
+	ActionMenuRight
+	ActionMenuBack
+	ActionsCount
+)
+
+var actionNames = [ActionsCount]string{
+	ActionSteerLeft:  "Steer left",
+	ActionSteerRight: "Steer right",
+	ActionThrust:     "Thrust",
+	ActionRestart:    "Restart",
+	ActionConfirm:    "Confirm",
+	ActionPause:      "Pause",
+	ActionMenuUp:     "Menu up",
+	ActionMenuDown:   "Menu down",
+	ActionMenuLeft:   "Menu left",
+	ActionMenuRight:  "Menu right",
+	ActionMenuBack:   "Menu back",
+}
+
+func (action Action) String() string {
+	return actionNames[action]
+}
+
+const gamepadPrefix = "Pad:"
+
+var gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "A",
+	ebiten.StandardGamepadButtonRightRight:       "B",
+	ebiten.StandardGamepadButtonRightLeft:        "X",
+	ebiten.StandardGamepadButtonRightTop:         "Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "Back",
+	ebiten.StandardGamepadButtonCenterRight:      "Start",
+	ebiten.StandardGamepadButtonCenterCenter:     "Home",
+	ebiten.StandardGamepadButtonLeftStick:        "LS",
+	ebiten.StandardGamepadButtonRightStick:       "RS",
+	ebiten.StandardGamepadButtonLeftTop:          "Up",
+	ebiten.StandardGamepadButtonLeftBottom:       "Down",
+	ebiten.StandardGamepadButtonLeftLeft:         "Left",
+	ebiten.StandardGamepadButtonLeftRight:        "Right",
+}
+
+// Binding is either a keyboard key or a standard gamepad button
+type Binding struct {
+	Gamepad bool
+	Key     ebiten.Key
+	Button  ebiten.StandardGamepadButton
+}
+
+func ParseBinding(name string) (Binding, error) {
+	if buttonName, found := strings.CutPrefix(name, gamepadPrefix); found {
+		for button, n := range gamepadButtonNames {
+			if strings.EqualFold(n, buttonName) {
+				return Binding{Gamepad: true, Button: button}, nil
+			}
+		}
+		return Binding{}, fmt.Errorf("unknown gamepad button %s", name)
+	}
+
+	var key ebiten.Key
+	if err := key.UnmarshalText([]byte(name)); err != nil {
+		return Binding{}, err
+	}
+	return Binding{Key: key}, nil
+}
+
+func (binding Binding) String() string {
+	if binding.Gamepad {
+		return gamepadPrefix + gamepadButtonNames[binding.Button]
+	}
+	return binding.Key.String()
+}
+
+/*
+Bindings resolves actions to the keys and gamepad buttons configured in
+settings.SettingsControls. Actions missing from the settings fall back to
+settings.DefaultControls, an empty list keeps the action unbound unless it is
+required
+*/
+type Bindings struct {
+	actions    [ActionsCount][]Binding
+	gamepadIds []ebiten.GamepadID
+}
+
+func NewBindings(controls settings.SettingsControls) (*Bindings, error) {
+	bindings := &Bindings{}
+	defaults := settings.DefaultControls()
+
+	for action := Action(0); action < ActionsCount; action++ {
+		names := *controlsField(&controls, action)
+		if names == nil || (len(names) == 0 && action.IsRequired()) {
+			names = *controlsField(&defaults, action)
+		}
+
+		for _, name := range names {
+			binding, err := ParseBinding(name)
+			if err != nil {
+				return nil, fmt.Errorf("controls %s: %w", action, err)
+			}
+			bindings.Add(action, binding)
+		}
+	}
+
+	return bindings, nil
+}
+
+func controlsField(controls *settings.SettingsControls, action Action) *[]string {
+	switch action {
+	case ActionSteerLeft:
+		return &controls.SteerLeft
+	case ActionSteerRight:
+		return &controls.SteerRight
+	case ActionThrust:
+		return &controls.Thrust
+	case ActionRestart:
+		return &controls.Restart
+	case ActionConfirm:
+		return &controls.Confirm
+	case ActionPause:
+		return &controls.Pause
+	case ActionMenuUp:
+		return &controls.MenuUp
+	case ActionMenuDown:
+		return &controls.MenuDown
+	case ActionMenuLeft:
+		return &controls.MenuLeft
+	case ActionMenuRight:
+		return &controls.MenuRight
+	case ActionMenuBack:
+		return &controls.MenuBack
+	}
+	panic(fmt.Sprintf("unknown action %d", action))
+}
+
+// Controls converts the bindings back to their settings representation
+func (bindings *Bindings) Controls() settings.SettingsControls {
+	var controls settings.SettingsControls
+	for action := Action(0); action < ActionsCount; action++ {
+		names := make([]string, 0, len(bindings.actions[action]))
+		for _, binding := range bindings.actions[action] {
+			names = append(names, binding.String())
+		}
+		*controlsField(&controls, action) = names
+	}
+	return controls
+}
+
+func (bindings *Bindings) Get(action Action) []Binding {
+	return bindings.actions[action]
+}
+
+// Describe names the keys and buttons bound to action, for the hints shown on screen
+func (bindings *Bindings) Describe(action Action) string {
+	names := make([]string, 0, len(bindings.actions[action]))
+	for _, binding := range bindings.actions[action] {
+		names = append(names, binding.String())
+	}
+	if len(names) == 0 {
+		return "(unbound)"
+	}
+	return strings.Join(names, "/")
+}
+
+func (bindings *Bindings) Add(action Action, binding Binding) {
+	for _, b := range bindings.actions[action] {
+		if b == binding {
+			return
+		}
+	}
+	bindings.actions[action] = append(bindings.actions[action], binding)
+}
+
+/*
+IsRequired reports whether action must keep at least one binding, without
+them the menus, including the rebinding screen itself, could not be used
+*/
+func (action Action) IsRequired() bool {
+	switch action {
+	case ActionConfirm, ActionMenuUp, ActionMenuDown, ActionMenuBack:
+		return true
+	}
+	return false
+}
+
+func (bindings *Bindings) Clear(action Action) {
+	bindings.actions[action] = nil
+}
+
+// keyValue is 1 when any key bound to action is held
+func (bindings *Bindings) keyValue(action Action) float64 {
+	for _, binding := range bindings.actions[action] {
+		if !binding.Gamepad && ebiten.IsKeyPressed(binding.Key) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// gamepadValue is the strongest value of the buttons bound to action, analog triggers report partial values
+func (bindings *Bindings) gamepadValue(action Action, id ebiten.GamepadID) float64 {
+	value := 0.0
+	for _, binding := range bindings.actions[action] {
+		if binding.Gamepad {
+			buttonValue := applyDeadZone(ebiten.StandardGamepadButtonValue(id, binding.Button), triggerDeadZone)
+			value = max(value, buttonValue)
+		}
+	}
+	return value
+}
+
+func (bindings *Bindings) IsJustPressed(action Action) bool {
+	bindings.gamepadIds = ebiten.AppendGamepadIDs(bindings.gamepadIds[:0])
+
+	for _, binding := range bindings.actions[action] {
+		if !binding.Gamepad {
+			if inpututil.IsKeyJustPressed(binding.Key) {
+				return true
+			}
+			continue
+		}
+
+		for _, id := range bindings.gamepadIds {
+			if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustPressed(id, binding.Button) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+CaptureJustPressed returns the first key or gamepad button pressed on this
+tick, used by the rebinding screen
+*/
+func CaptureJustPressed() (Binding, bool) {
+	keys := inpututil.AppendJustPressedKeys(nil)
+	if len(keys) > 0 {
+		return Binding{Key: keys[0]}, true
+	}
+
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		buttons := inpututil.AppendJustPressedStandardGamepadButtons(id, nil)
+		if len(buttons) > 0 {
+			return Binding{Gamepad: true, Button: buttons[0]}, true
+		}
+	}
+
+	return Binding{}, false
+}
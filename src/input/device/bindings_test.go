@@ -0,0 +1,41 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/abelroes/gmtk2024/src/settings"
+)
+
+func TestNewBindingsKeepsClearedActions(t *testing.T) {
+	bindings, err := NewBindings(settings.DefaultControls())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindings.Clear(ActionPause)
+	bindings.Clear(ActionConfirm)
+
+	saved := settings.DefaultSettings.Clone()
+	saved.Controls = bindings.Controls()
+	data, err := settings.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := settings.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewBindings(loaded.Controls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pause := got.Get(ActionPause); len(pause) != 0 {
+		t.Errorf("cleared Pause came back as %v", pause)
+	}
+	if confirm := got.Get(ActionConfirm); len(confirm) == 0 {
+		t.Error("required Confirm was left without bindings")
+	}
+	if thrust := got.Get(ActionThrust); len(thrust) != len(settings.DefaultControls().Thrust) {
+		t.Errorf("Thrust got %v", thrust)
+	}
+}
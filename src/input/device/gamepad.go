@@ -0,0 +1,66 @@
+package device
+
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	stickDeadZone   = 0.2
+	triggerDeadZone = 0.05
+)
+
+/*
+Gamepad reads every connected gamepad that has a standard layout. Buttons
+come from the bindings, so an analog trigger bound to thrust reports its
+pressure as a partial thrust, and the left stick always steers
+*/
+type Gamepad struct {
+	bindings *Bindings
+	ids      []ebiten.GamepadID
+}
+
+func NewGamepad(bindings *Bindings) *Gamepad {
+	return &Gamepad{bindings: bindings}
+}
+
+func (gamepad *Gamepad) Poll() input.State {
+	var state input.State
+
+	gamepad.ids = ebiten.AppendGamepadIDs(gamepad.ids[:0])
+	for _, id := range gamepad.ids {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		stick := applyDeadZone(ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal), stickDeadZone)
+		left := max(-stick, gamepad.bindings.gamepadValue(ActionSteerLeft, id))
+		right := max(stick, gamepad.bindings.gamepadValue(ActionSteerRight, id))
+		thrust := gamepad.bindings.gamepadValue(ActionThrust, id)
+
+		state.SteerLeft = max(state.SteerLeft, left)
+		state.SteerRight = max(state.SteerRight, right)
+		state.Thrust = max(state.Thrust, thrust)
+	}
+
+	return state
+}
+
+/*
+applyDeadZone zeroes values whose magnitude is below deadZone and rescales the
+rest so the output still covers the whole [-1, 1] range
+*/
+func applyDeadZone(value, deadZone float64) float64 {
+	magnitude := min(math.Abs(value), 1)
+	if magnitude < deadZone {
+		return 0
+	}
+
+	scaled := (magnitude - deadZone) / (1 - deadZone)
+	if value < 0 {
+		return -scaled
+	}
+	return scaled
+}
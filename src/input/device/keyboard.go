@@ -0,0 +1,19 @@
+package device
+
+import "github.com/abelroes/gmtk2024/src/input"
+
+type Keyboard struct {
+	bindings *Bindings
+}
+
+func NewKeyboard(bindings *Bindings) *Keyboard {
+	return &Keyboard{bindings: bindings}
+}
+
+func (keyboard *Keyboard) Poll() input.State {
+	return input.State{
+		SteerLeft:  keyboard.bindings.keyValue(ActionSteerLeft),
+		SteerRight: keyboard.bindings.keyValue(ActionSteerRight),
+		Thrust:     keyboard.bindings.keyValue(ActionThrust),
+	}
+}
@@ -0,0 +1,28 @@
+package device
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+func isAnyGamepadButtonJustPressed(buttons ...ebiten.StandardGamepadButton) bool {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		for _, button := range buttons {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsClearJustPressed is fixed, it is how bindings are removed on the rebinding screen
+func IsClearJustPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyBackspace) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyDelete) ||
+		isAnyGamepadButtonJustPressed(ebiten.StandardGamepadButtonRightLeft)
+}
@@ -0,0 +1,62 @@
+package input
+
+import "math"
+
+/*
+State is what the player asked the ship to do during a single tick, every
+field is a strength in [0, 1] so analog sticks and triggers can apply a
+partial amount, digital inputs are either 0 or 1
+*/
+type State struct {
+	SteerLeft  float64
+	SteerRight float64
+	Thrust     float64
+}
+
+// Source yields the player's input, Poll is called exactly once per simulation tick
+type Source interface {
+	Poll() State
+}
+
+const quantizationSteps = 255
+
+/*
+Quantize snaps every field to one of 256 steps, the resolution replays store
+inputs with, so a live run and its playback feed the exact same values
+*/
+func (state State) Quantize() State {
+	return State{
+		SteerLeft:  Dequantize(QuantizeValue(state.SteerLeft)),
+		SteerRight: Dequantize(QuantizeValue(state.SteerRight)),
+		Thrust:     Dequantize(QuantizeValue(state.Thrust)),
+	}
+}
+
+func QuantizeValue(value float64) byte {
+	return byte(math.Round(min(max(value, 0), 1) * quantizationSteps))
+}
+
+func Dequantize(value byte) float64 {
+	return float64(value) / quantizationSteps
+}
+
+type mergedSource []Source
+
+/*
+Merge combines sources so that an action is as strong as the strongest of
+them, e.g. keyboard and gamepad at the same time
+*/
+func Merge(sources ...Source) Source {
+	return mergedSource(sources)
+}
+
+func (sources mergedSource) Poll() State {
+	var state State
+	for _, source := range sources {
+		s := source.Poll()
+		state.SteerLeft = max(state.SteerLeft, s.SteerLeft)
+		state.SteerRight = max(state.SteerRight, s.SteerRight)
+		state.Thrust = max(state.Thrust, s.Thrust)
+	}
+	return state
+}
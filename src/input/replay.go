@@ -0,0 +1,29 @@
+package input
+
+// Replay feeds back a recorded sequence of states, one per tick
+type Replay struct {
+	states []State
+	tick   int
+}
+
+func NewReplay(states []State) *Replay {
+	return &Replay{states: states}
+}
+
+func (replay *Replay) Poll() State {
+	if replay.Done() {
+		return State{}
+	}
+
+	state := replay.states[replay.tick]
+	replay.tick++
+	return state
+}
+
+func (replay *Replay) Done() bool {
+	return replay.tick >= len(replay.states)
+}
+
+func (replay *Replay) Rewind() {
+	replay.tick = 0
+}
@@ -0,0 +1,20 @@
+package input
+
+/*
+Script delegates every tick to a function, used for scripted sequences and
+AI/bot control. tick starts at 0 and increments on each Poll
+*/
+type Script struct {
+	control func(tick int) State
+	tick    int
+}
+
+func NewScript(control func(tick int) State) *Script {
+	return &Script{control: control}
+}
+
+func (script *Script) Poll() State {
+	state := script.control(script.tick)
+	script.tick++
+	return state
+}
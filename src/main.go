@@ -1,9 +1,24 @@
 package main
 
-import "github.com/abelroes/gmtk2024/src/game"
+import (
+	"flag"
+
+	"github.com/abelroes/gmtk2024/src/game"
+)
 
 func main() {
-	err := game.Main()
+	var opts game.LaunchOptions
+	flag.StringVar(&opts.SettingsPath, "settings", "", "settings file to use instead of settings.json")
+	flag.StringVar(&opts.Levels, "levels", "", "TMX file, directory or zip of levels to play instead of the campaign")
+	flag.StringVar(&opts.Level, "level", "", "level to start at, by number (starting at 1) or name")
+	flag.BoolVar(&opts.SkipMenu, "skip-menu", false, "start playing right away")
+	flag.BoolVar(&opts.Windowed, "windowed", false, "run in a window")
+	flag.BoolVar(&opts.Fullscreen, "fullscreen", false, "run fullscreen")
+	flag.StringVar(&opts.Replay, "replay", "", "replay file to play back")
+	flag.StringVar(&opts.Record, "record", "", "replay file every level attempt is written to")
+	flag.Parse()
+
+	err := game.Main(opts)
 	if err != nil {
 		panic(err)
 	}
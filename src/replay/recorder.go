@@ -0,0 +1,38 @@
+package replay
+
+import "github.com/abelroes/gmtk2024/src/input"
+
+/*
+Recorder is an input.Source that forwards another source and keeps every
+state it handed out, quantized so the recording plays back exactly
+*/
+type Recorder struct {
+	source input.Source
+	inputs []input.State
+}
+
+func NewRecorder(source input.Source) *Recorder {
+	return &Recorder{source: source}
+}
+
+func (recorder *Recorder) Poll() input.State {
+	state := recorder.source.Poll().Quantize()
+	recorder.inputs = append(recorder.inputs, state)
+	return state
+}
+
+// Reset drops what was recorded so far, called when a new attempt starts
+func (recorder *Recorder) Reset() {
+	recorder.inputs = recorder.inputs[:0]
+}
+
+func (recorder *Recorder) Replay(header Header) *Replay {
+	inputs := make([]input.State, len(recorder.inputs))
+	copy(inputs, recorder.inputs)
+
+	header.FormatVersion = FormatVersion
+	return &Replay{
+		Header: header,
+		Inputs: inputs,
+	}
+}
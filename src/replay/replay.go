@@ -0,0 +1,230 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abelroes/gmtk2024/src/input"
+)
+
+/*
+File layout, integers are unsigned varints unless stated otherwise:
+
+	magic "SDRP"
+	format version
+	pack title length, pack title bytes
+	level index
+	game version length, game version bytes
+	simulation hash (8 bytes, little endian)
+	tick count
+	runs of identical ticks: run length, steer left, steer right, thrust (1 byte each)
+*/
+const (
+	magic         = "SDRP"
+	FormatVersion = 1
+	// maxTicks guards against allocating absurd amounts of memory when reading a corrupted file
+	maxTicks = 1 << 24
+	// maxStringLen bounds the pack title and the game version for the same reason
+	maxStringLen = 256
+)
+
+type Header struct {
+	FormatVersion int
+	// Pack is the title of the level pack LevelIndex refers to, empty for the embedded campaign
+	Pack        string
+	LevelIndex  int
+	GameVersion string
+	// SimulationHash identifies the level layout and ship the replay was recorded with, see simulation.Config.Hash
+	SimulationHash uint64
+}
+
+type Replay struct {
+	Header Header
+	// Inputs holds one quantized input.State per simulation tick
+	Inputs []input.State
+}
+
+func (replay *Replay) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, magic...)
+	buf = binary.AppendUvarint(buf, FormatVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(replay.Header.Pack)))
+	buf = append(buf, replay.Header.Pack...)
+	buf = binary.AppendUvarint(buf, uint64(replay.Header.LevelIndex))
+	buf = binary.AppendUvarint(buf, uint64(len(replay.Header.GameVersion)))
+	buf = append(buf, replay.Header.GameVersion...)
+	buf = binary.LittleEndian.AppendUint64(buf, replay.Header.SimulationHash)
+	buf = binary.AppendUvarint(buf, uint64(len(replay.Inputs)))
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(replay.Inputs); {
+		state := replay.Inputs[start]
+		end := start + 1
+		for end < len(replay.Inputs) && replay.Inputs[end] == state {
+			end++
+		}
+
+		buf = binary.AppendUvarint(buf[:0], uint64(end-start))
+		buf = append(buf,
+			input.QuantizeValue(state.SteerLeft),
+			input.QuantizeValue(state.SteerRight),
+			input.QuantizeValue(state.Thrust),
+		)
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	return bw.Flush()
+}
+
+func Read(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+
+	fileMagic := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, fileMagic); err != nil {
+		return nil, err
+	}
+	if string(fileMagic) != magic {
+		return nil, errors.New("not a replay file")
+	}
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if version != FormatVersion {
+		return nil, fmt.Errorf("unsupported replay format version %d", version)
+	}
+
+	pack, err := readString(br, "pack title")
+	if err != nil {
+		return nil, err
+	}
+
+	levelIndex, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	gameVersion, err := readString(br, "game version")
+	if err != nil {
+		return nil, err
+	}
+
+	var simulationHash uint64
+	if err := binary.Read(br, binary.LittleEndian, &simulationHash); err != nil {
+		return nil, err
+	}
+
+	ticks, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if ticks > maxTicks {
+		return nil, fmt.Errorf("replay too long, %d ticks", ticks)
+	}
+
+	inputs := make([]input.State, 0, ticks)
+	run := make([]byte, 3)
+	for uint64(len(inputs)) < ticks {
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 || uint64(len(inputs))+count > ticks {
+			return nil, fmt.Errorf("invalid run of %d ticks", count)
+		}
+
+		if _, err := io.ReadFull(br, run); err != nil {
+			return nil, err
+		}
+		state := input.State{
+			SteerLeft:  input.Dequantize(run[0]),
+			SteerRight: input.Dequantize(run[1]),
+			Thrust:     input.Dequantize(run[2]),
+		}
+
+		for i := uint64(0); i < count; i++ {
+			inputs = append(inputs, state)
+		}
+	}
+
+	return &Replay{
+		Header: Header{
+			FormatVersion:  int(version),
+			Pack:           pack,
+			LevelIndex:     int(levelIndex),
+			GameVersion:    gameVersion,
+			SimulationHash: simulationHash,
+		},
+		Inputs: inputs,
+	}, nil
+}
+
+func readString(br *bufio.Reader, name string) (string, error) {
+	length, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	if length > maxStringLen {
+		return "", fmt.Errorf("invalid %s length %d", name, length)
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(br, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	replay, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading replay %s: %w", path, err)
+	}
+	return replay, nil
+}
+
+// Save writes replay to a new file in dir named after its level and the current time, returning its path
+func Save(dir string, replay *Replay) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("lvl%d-%s.replay", replay.Header.LevelIndex+1, time.Now().Format("20060102-150405.000"))
+	path := filepath.Join(dir, name)
+	return path, SaveFile(path, replay)
+}
+
+// SaveFile writes replay to path, replacing the file if it already exists
+func SaveFile(path string, replay *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := replay.Write(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,176 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/storage"
+)
+
+const (
+	// Version is bumped whenever Data changes in a way older saves need a migration for
+	Version = 1
+	key     = "save.json"
+)
+
+// ErrNotWritable is wrapped by Load when the stored save could not be read nor backed up, writing over it would lose it
+var ErrNotWritable = errors.New("progress is not saved this session")
+
+type PersonalBests struct {
+	// Splits holds the best time of each level during full game runs, by level index
+	Splits map[int]clock.Tick
+	// FullGame is the best lvl1 to final level time, 0 when never finished
+	FullGame clock.Tick
+	// IndividualLevels holds the best time of each level in individual level mode
+	IndividualLevels map[int]clock.Tick
+}
+
+func (bests PersonalBests) Clone() PersonalBests {
+	clone := PersonalBests{
+		Splits:           make(map[int]clock.Tick, len(bests.Splits)),
+		FullGame:         bests.FullGame,
+		IndividualLevels: make(map[int]clock.Tick, len(bests.IndividualLevels)),
+	}
+	for level, ticks := range bests.Splits {
+		clone.Splits[level] = ticks
+	}
+	for level, ticks := range bests.IndividualLevels {
+		clone.IndividualLevels[level] = ticks
+	}
+	return clone
+}
+
+type Stats struct {
+	Attempts            int
+	Wins                int
+	GamesFinished       int
+	DeathsByCollision   int
+	DeathsByShrinking   int
+	DeathsByOutOfBounds int
+	PlayTime            clock.Tick
+}
+
+func (stats Stats) Deaths() int {
+	return stats.DeathsByCollision + stats.DeathsByShrinking + stats.DeathsByOutOfBounds
+}
+
+type Data struct {
+	Version int
+	// Completed holds every level index that was beaten at least once, levels unlock from it
+	Completed map[int]bool
+	Bests     PersonalBests
+	Stats     Stats
+}
+
+func New() *Data {
+	data := &Data{Version: Version}
+	data.init()
+	return data
+}
+
+// init makes sure maps are usable after decoding a save that had them empty
+func (data *Data) init() {
+	if data.Completed == nil {
+		data.Completed = map[int]bool{}
+	}
+	if data.Bests.Splits == nil {
+		data.Bests.Splits = map[int]clock.Tick{}
+	}
+	if data.Bests.IndividualLevels == nil {
+		data.Bests.IndividualLevels = map[int]clock.Tick{}
+	}
+}
+
+// IsUnlocked reports whether the level is the first one, was completed or follows a completed one
+func (data *Data) IsUnlocked(levelIndex int) bool {
+	return levelIndex == 0 || data.Completed[levelIndex] || data.Completed[levelIndex-1]
+}
+
+func (data *Data) Write(store storage.Storage) error {
+	data.Version = Version
+
+	raw, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return err
+	}
+	return store.Save(key, raw)
+}
+
+/*
+Load reads the save from store, migrating it when it was written by an older
+version. A corrupted save, or one written by a newer version of the game, is
+backed up next to the original and replaced by a fresh one: in that case both
+the fresh save and an error describing what happened are returned. When the
+save cannot be read or backed up the error wraps ErrNotWritable
+*/
+func Load(store storage.Storage) (*Data, error) {
+	raw, err := store.Load(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return New(), nil
+	}
+	if err != nil {
+		return New(), fmt.Errorf("%w, %w", err, ErrNotWritable)
+	}
+
+	var header struct {
+		Version int
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return New(), backup(store, raw, "corrupted", err)
+	}
+
+	if header.Version > Version {
+		return New(), backup(store, raw, fmt.Sprintf("v%d", header.Version), fmt.Errorf("save version %d is newer than %d", header.Version, Version))
+	}
+
+	raw, err = migrate(raw, header.Version)
+	if err != nil {
+		return New(), backup(store, raw, "corrupted", err)
+	}
+
+	data := New()
+	if err := json.Unmarshal(raw, data); err != nil {
+		return New(), backup(store, raw, "corrupted", err)
+	}
+	data.init()
+	return data, nil
+}
+
+func backup(store storage.Storage, raw []byte, suffix string, cause error) error {
+	backupKey := key + "." + suffix
+	if err := store.Save(backupKey, raw); err != nil {
+		return fmt.Errorf("%w, and backing it up failed: %w, %w", cause, err, ErrNotWritable)
+	}
+	return fmt.Errorf("%w, backed up as %s and started a new save", cause, backupKey)
+}
+
+/*
+migrations[v] upgrades a save from version v to v+1, working on the raw JSON
+object so fields that were renamed or removed can still be read
+*/
+var migrations = map[int]func(fields map[string]json.RawMessage) error{}
+
+func migrate(raw []byte, version int) ([]byte, error) {
+	if version == Version {
+		return raw, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw, err
+	}
+
+	for v := version; v < Version; v++ {
+		migration, found := migrations[v]
+		if !found {
+			return raw, fmt.Errorf("no migration from save version %d", v)
+		}
+		if err := migration(fields); err != nil {
+			return raw, err
+		}
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,189 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version is bumped whenever Settings changes in a way older files need a migration for
+const Version = 1
+
+/*
+migrations[v] upgrades settings from version v to v+1, working on the raw
+JSON object so fields that were renamed or removed can still be read. None
+is needed yet
+*/
+var migrations = map[int]func(fields map[string]json.RawMessage) error{}
+
+// comments are written above their field in settings.json, by dotted path
+var comments = map[string]string{
+	"Version":            "schema of this file, do not edit",
+	"Volume":             "from 0 (muted) to 1",
+	"Screen":             "window size in pixels, the game is drawn at 640x480 and scaled to it",
+	"Controls":           `ebiten key names ("A", "ArrowLeft", "Space"...) or gamepad buttons prefixed with "Pad:" ("Pad:A", "Pad:RT"...)`,
+	"Replay.Dir":         "every level attempt is recorded here, empty disables recording",
+	"Replay.Playback":    "replay file played back as soon as the game starts",
+	"Replay.Record":      "file every level attempt overwrites, empty disables it",
+	"Speedrun.ShowTimer": "show the splits on the top right while playing",
+	"Debug":              "developer options, InitialLevel is the index of the level Start begins with",
+}
+
+/*
+Parse reads a settings.json written by any version of the game. Fields
+missing from data keep their DefaultSettings value and // comments are
+ignored. The result is not validated, see Settings.Validate
+*/
+func Parse(data []byte) (*Settings, error) {
+	data = stripComments(data)
+
+	var header struct {
+		Version int
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+
+	// files written before the schema was versioned only lack the Version field
+	if header.Version == 0 {
+		header.Version = 1
+	}
+
+	if header.Version > Version {
+		return nil, fmt.Errorf("settings: version %d is newer than %d", header.Version, Version)
+	}
+
+	data, err := migrate(data, header.Version)
+	if err != nil {
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+
+	settings := DefaultSettings.Clone()
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+	settings.Version = Version
+	return settings, nil
+}
+
+func migrate(data []byte, version int) ([]byte, error) {
+	if version == Version {
+		return data, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data, err
+	}
+
+	for v := version; v < Version; v++ {
+		migration, found := migrations[v]
+		if !found {
+			return data, fmt.Errorf("no migration from version %d", v)
+		}
+		if err := migration(fields); err != nil {
+			return data, err
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// stripComments drops every // comment outside of strings
+func stripComments(data []byte) []byte {
+	stripped := make([]byte, 0, len(data))
+	inString, escaped, inComment := false, false, false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inComment:
+			if c != '\n' {
+				continue
+			}
+			inComment = false
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			inComment = true
+			continue
+		}
+		stripped = append(stripped, c)
+	}
+
+	return stripped
+}
+
+// Validate rejects values the game cannot run with, levelCount is the number of levels loaded
+func (settings *Settings) Validate(levelCount int) error {
+	var errs []error
+
+	volumes := []struct {
+		name  string
+		value float64
+	}{
+		{"Volume.SoundTrack", settings.Volume.SoundTrack},
+		{"Volume.SoundFx", settings.Volume.SoundFx},
+	}
+	for _, volume := range volumes {
+		if volume.value < 0 || volume.value > 1 {
+			errs = append(errs, fmt.Errorf("%s is %v, it must be between 0 and 1", volume.name, volume.value))
+		}
+	}
+
+	if settings.Screen.Width <= 0 || settings.Screen.Height <= 0 {
+		errs = append(errs, fmt.Errorf("Screen is %dx%d, both sides must be positive", settings.Screen.Width, settings.Screen.Height))
+	}
+
+	if settings.Debug.InitialLevel < 0 || settings.Debug.InitialLevel >= levelCount {
+		errs = append(errs, fmt.Errorf("Debug.InitialLevel is %d, there are only levels 0 to %d", settings.Debug.InitialLevel, levelCount-1))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid settings: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+var fieldLine = regexp.MustCompile(`^(\t*)"(\w+)":`)
+
+// Marshal encodes the settings as indented JSON with a comment above the fields that need explaining
+func Marshal(settings *Settings) ([]byte, error) {
+	versioned := *settings
+	versioned.Version = Version
+
+	data, err := json.MarshalIndent(&versioned, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		out  bytes.Buffer
+		path []string
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		if match := fieldLine.FindStringSubmatch(line); match != nil {
+			depth := len(match[1])
+			path = append(path[:depth-1], match[2])
+			if comment, found := comments[strings.Join(path, ".")]; found {
+				fmt.Fprintf(&out, "%s// %s\n", match[1], comment)
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), nil
+}
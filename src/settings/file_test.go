@@ -0,0 +1,184 @@
+package settings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no comments", `{"A": 1}`, `{"A": 1}`},
+		{"whole line", "// comment\n{\"A\": 1}", "\n{\"A\": 1}"},
+		{"end of line", "{\"A\": 1} // comment\n}", "{\"A\": 1} \n}"},
+		{"last line", `{"A": 1} // comment`, `{"A": 1} `},
+		{"inside string", `{"A": "http://example"}`, `{"A": "http://example"}`},
+		{"escaped quote inside string", `{"A": "\"//\""} // comment`, `{"A": "\"//\""} `},
+		{"escaped backslash ends string", `{"A": "\\"} // comment`, `{"A": "\\"} `},
+		{"single slash", `{"A": "a/b", "B": 1 / 2}`, `{"A": "a/b", "B": 1 / 2}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := string(stripComments([]byte(test.in))); got != test.want {
+				t.Errorf("stripComments(%q) = %q, want %q", test.in, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("comments and slashes inside strings", func(t *testing.T) {
+		settings, err := Parse([]byte(`{
+			// comment
+			"Version": 1,
+			"Replay": {
+				"Dir": "//server/replays", // trailing comment
+				"Playback": "C:\\replays\\best.replay"
+			}
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if settings.Replay.Dir != "//server/replays" || settings.Replay.Playback != `C:\replays\best.replay` {
+			t.Errorf("got Replay %+v", settings.Replay)
+		}
+	})
+
+	t.Run("missing fields keep the defaults", func(t *testing.T) {
+		settings, err := Parse([]byte(`{"Version": 1, "Volume": {"SoundFx": 0.5}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if settings.Volume.SoundFx != .5 || settings.Volume.SoundTrack != DefaultSettings.Volume.SoundTrack {
+			t.Errorf("got Volume %+v", settings.Volume)
+		}
+		if !reflect.DeepEqual(settings.Controls, DefaultSettings.Controls) {
+			t.Errorf("got Controls %+v", settings.Controls)
+		}
+	})
+
+	t.Run("does not touch the defaults", func(t *testing.T) {
+		settings, err := Parse([]byte(`{"Version": 1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings.Controls.Thrust[0] = "Changed"
+		if DefaultSettings.Controls.Thrust[0] == "Changed" {
+			t.Error("the parsed settings share their slices with DefaultSettings")
+		}
+	})
+
+	t.Run("cleared controls stay empty", func(t *testing.T) {
+		settings, err := Parse([]byte(`{"Version": 1, "Controls": {"Pause": []}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if settings.Controls.Pause == nil || len(settings.Controls.Pause) != 0 {
+			t.Errorf("got Pause %#v, want an empty list", settings.Controls.Pause)
+		}
+	})
+
+	t.Run("unversioned file", func(t *testing.T) {
+		settings, err := Parse([]byte(`{"Screen": {"Width": 800, "Height": 600}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if settings.Version != Version || settings.Screen.Width != 800 || settings.Screen.Height != 600 {
+			t.Errorf("got %+v", settings)
+		}
+	})
+
+	invalid := []struct {
+		name string
+		in   string
+	}{
+		{"newer version", `{"Version": 1000}`},
+		{"invalid json", `{"Version": 1,}`},
+		{"wrong type", `{"Version": 1, "Volume": {"SoundFx": "loud"}}`},
+		{"comment hides the closing brace", `{"Version": 1 // }`},
+	}
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse([]byte(test.in)); err == nil {
+				t.Errorf("Parse(%q) succeeded", test.in)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	want := DefaultSettings.Clone()
+	want.Replay.Dir = "//server/replays"
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "// "+comments["Volume"]) {
+		t.Errorf("no comment above Volume in\n%s", data)
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const levelCount = 3
+
+	tests := []struct {
+		name   string
+		change func(*Settings)
+		errs   []string
+	}{
+		{"defaults", func(*Settings) {}, nil},
+		{"full volume", func(s *Settings) { s.Volume.SoundTrack, s.Volume.SoundFx = 1, 0 }, nil},
+		{"last level", func(s *Settings) { s.Debug.InitialLevel = levelCount - 1 }, nil},
+		{"negative volume", func(s *Settings) { s.Volume.SoundFx = -.1 }, []string{"Volume.SoundFx"}},
+		{"loud volume", func(s *Settings) { s.Volume.SoundTrack = 1.5 }, []string{"Volume.SoundTrack"}},
+		{"empty screen", func(s *Settings) { s.Screen.Height = 0 }, []string{"Screen"}},
+		{"negative level", func(s *Settings) { s.Debug.InitialLevel = -1 }, []string{"Debug.InitialLevel"}},
+		{"missing level", func(s *Settings) { s.Debug.InitialLevel = levelCount }, []string{"Debug.InitialLevel"}},
+		{
+			"every error is reported",
+			func(s *Settings) {
+				s.Volume.SoundFx = 2
+				s.Screen.Width = -1
+				s.Debug.InitialLevel = levelCount
+			},
+			[]string{"Volume.SoundFx", "Screen", "Debug.InitialLevel"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := DefaultSettings.Clone()
+			test.change(settings)
+
+			err := settings.Validate(levelCount)
+			if len(test.errs) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, field := range test.errs {
+				if !strings.Contains(err.Error(), field) {
+					t.Errorf("error %q does not mention %s", err, field)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package settings
 
 import (
+	"slices"
+
 	"github.com/abelroes/gmtk2024/src/constants"
 )
 
@@ -20,15 +22,89 @@ type SettingsDebug struct {
 
 type SettingsScreen struct {
 	Width, Height int
+	Fullscreen    bool
+}
+
+/*
+SettingsControls maps every action to the keys or gamepad buttons bound to it.
+Keys use ebiten's key names ("A", "ArrowLeft", "Space"...) and gamepad
+buttons are prefixed with "Pad:" ("Pad:A", "Pad:RT", "Pad:Start"...)
+*/
+type SettingsControls struct {
+	SteerLeft  []string
+	SteerRight []string
+	Thrust     []string
+	Restart    []string
+	Confirm    []string
+	Pause      []string
+	MenuUp     []string
+	MenuDown   []string
+	MenuLeft   []string
+	MenuRight  []string
+	MenuBack   []string
+}
+
+type SettingsReplay struct {
+	// Dir is where every level attempt is saved, empty disables recording
+	Dir string
+	// Playback is a replay file played back as soon as the game starts
+	Playback string
+	// Record is a file every level attempt overwrites, so the last one is always at the same place
+	Record string
+}
+
+type SettingsSpeedrun struct {
+	ShowTimer bool
 }
 
 type Settings struct {
-	Volume SettingsVolume
-	Screen SettingsScreen
-	Debug  SettingsDebug
+	// Version is the schema settings.json was written with, older files are migrated when loaded
+	Version  int
+	Volume   SettingsVolume
+	Screen   SettingsScreen
+	Controls SettingsControls
+	Replay   SettingsReplay
+	Speedrun SettingsSpeedrun
+	Debug    SettingsDebug
+}
+
+// Clone deep copies the settings, so decoding into the clone never touches the original slices
+func (settings *Settings) Clone() *Settings {
+	clone := *settings
+	clone.Controls = SettingsControls{
+		SteerLeft:  slices.Clone(settings.Controls.SteerLeft),
+		SteerRight: slices.Clone(settings.Controls.SteerRight),
+		Thrust:     slices.Clone(settings.Controls.Thrust),
+		Restart:    slices.Clone(settings.Controls.Restart),
+		Confirm:    slices.Clone(settings.Controls.Confirm),
+		Pause:      slices.Clone(settings.Controls.Pause),
+		MenuUp:     slices.Clone(settings.Controls.MenuUp),
+		MenuDown:   slices.Clone(settings.Controls.MenuDown),
+		MenuLeft:   slices.Clone(settings.Controls.MenuLeft),
+		MenuRight:  slices.Clone(settings.Controls.MenuRight),
+		MenuBack:   slices.Clone(settings.Controls.MenuBack),
+	}
+	return &clone
+}
+
+func DefaultControls() SettingsControls {
+	return SettingsControls{
+		SteerLeft:  []string{"ArrowLeft", "A", "Pad:Left"},
+		SteerRight: []string{"ArrowRight", "D", "Pad:Right"},
+		Thrust:     []string{"ArrowUp", "W", "Space", "Pad:A", "Pad:RT"},
+		Restart:    []string{"R", "Enter", "Pad:Start", "Pad:Y"},
+		Confirm:    []string{"Enter", "NumpadEnter", "Pad:A", "Pad:Start"},
+		Pause:      []string{"Escape", "P", "Pad:Start"},
+		MenuUp:     []string{"ArrowUp", "Pad:Up"},
+		MenuDown:   []string{"ArrowDown", "Pad:Down"},
+		MenuLeft:   []string{"ArrowLeft", "Pad:Left"},
+		MenuRight:  []string{"ArrowRight", "Pad:Right"},
+		MenuBack:   []string{"Escape", "Pad:B"},
+	}
 }
 
 var DefaultSettings = &Settings{
+	Version: Version,
 	Volume: SettingsVolume{
 		SoundTrack: .65,
 		SoundFx:    1,
@@ -37,4 +113,11 @@ var DefaultSettings = &Settings{
 		Width:  constants.Width * 1.5,
 		Height: constants.Height * 1.5,
 	},
+	Controls: DefaultControls(),
+	Replay: SettingsReplay{
+		Dir: "replays",
+	},
+	Speedrun: SettingsSpeedrun{
+		ShowTimer: true,
+	},
 }
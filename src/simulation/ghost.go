@@ -0,0 +1,10 @@
+package simulation
+
+import "github.com/abelroes/gmtk2024/src/vector"
+
+// GhostFrame is the player's transform during a single tick
+type GhostFrame struct {
+	Pos   vector.Vector2
+	Rot   float64
+	Scale float64
+}
@@ -0,0 +1,37 @@
+package simulation
+
+import (
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+type Goal struct {
+	Pos      vector.Vector2
+	Radius   float64
+	Collider collision.CollisionCircle
+	Angle    float64
+}
+
+const (
+	rotationSpeed = .01
+)
+
+func NewGoal(radius float64) Goal {
+	return Goal{
+		Radius: radius,
+	}
+}
+
+func (goal *Goal) SetPos(pos vector.Vector2) {
+	goal.Angle = 0
+	goal.Pos = pos
+	colliderFactor := .75
+	goal.Collider = collision.CollisionCircle{
+		Center: goal.Pos,
+		Radius: goal.Radius * colliderFactor,
+	}
+}
+
+func (goal *Goal) Update() {
+	goal.Angle += rotationSpeed
+}
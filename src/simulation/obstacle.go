@@ -0,0 +1,34 @@
+package simulation
+
+import (
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+/*
+Obstacle is a rock or cave wall made from a Tiled polygon, or from a
+polyline when it is not Closed. Parts is its convex decomposition, a
+polyline is made of one segment per part
+*/
+type Obstacle struct {
+	Vertices []vector.Vector2
+	Closed   bool
+	Parts    []collision.CollisionPolygon
+}
+
+func NewObstacle(vertices []vector.Vector2, closed bool) Obstacle {
+	obstacle := Obstacle{
+		Vertices: vertices,
+		Closed:   closed,
+	}
+
+	if closed {
+		obstacle.Parts = collision.Decompose(collision.CollisionPolygon{Vertices: vertices})
+		return obstacle
+	}
+
+	for i := 0; i+1 < len(vertices); i++ {
+		obstacle.Parts = append(obstacle.Parts, collision.CollisionPolygon{Vertices: vertices[i : i+2]})
+	}
+	return obstacle
+}
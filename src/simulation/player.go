@@ -0,0 +1,214 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/constants"
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+const (
+	initialManeuverability = 0.1
+	airFriction            = 0.1
+	scaleFactor            = 0.005
+	initialScale           = .5
+	constantPropulsion     = 0.0
+	minimumScale           = 0.03
+	oobMargin              = 50
+)
+
+type PlayerEvent int
+
+const (
+	PlayerDiedByCollision = iota
+	PlayerDiedByShrinking
+	PlayerDiedByOutOfBounds
+)
+
+type Player struct {
+	Dead            bool
+	Scale           float64
+	Rot             float64
+	Propulsion      float64
+	Pos             vector.Vector2
+	Vel             vector.Vector2
+	Acl             vector.Vector2
+	Maneuverability float64
+	MinimumScale    float64
+	Collisor        collision.CollisionPolygon
+	basePolygon     []vector.Vector2
+	eventHandler    func(PlayerEvent)
+	bounds          collision.CollisionRect
+	clock           *clock.Clock
+	input           input.Source
+
+	// PrevCollisor is Collisor as it was when the tick started, for swept collision
+	PrevCollisor collision.CollisionPolygon
+	// PrevPos is Pos as it was when the tick started
+	PrevPos     vector.Vector2
+	hasCollider bool
+
+	// Thrust is how hard the player thrusted during the last tick, 0 when it didn't
+	Thrust float64
+	// w and h are the sprite's size at scale 1
+	w, h float64
+}
+
+func NewPlayer(clock *clock.Clock, w, h float64, basePolygon []vector.Vector2, eventHandler func(PlayerEvent)) Player {
+	return Player{
+		Scale:           initialScale,
+		w:               w,
+		h:               h,
+		Maneuverability: initialManeuverability,
+		Collisor:        collision.CollisionPolygon{Vertices: make([]vector.Vector2, len(basePolygon))},
+		PrevCollisor:    collision.CollisionPolygon{Vertices: make([]vector.Vector2, len(basePolygon))},
+		basePolygon:     basePolygon,
+		MinimumScale:    minimumScale,
+		eventHandler:    eventHandler,
+		bounds: collision.CollisionRect{
+			Pos: vector.New(-oobMargin, -oobMargin),
+			W:   constants.Width + (oobMargin * 2),
+			H:   constants.Height + (oobMargin * 2),
+		},
+		clock: clock,
+	}
+}
+
+func (player *Player) Reset() {
+	player.Dead = false
+	player.Rot = 0
+	player.Vel.Set(0, 0)
+	player.Acl.Set(0, 0)
+	player.Scale = initialScale
+	player.Thrust = 0
+	player.hasCollider = false
+}
+
+func (player *Player) updateCollider(sin, cos float64) {
+	for i := 0; i < len(player.Collisor.Vertices); i++ {
+		var (
+			base = player.basePolygon[i]
+			// vector rotation
+			x = cos*base.X - sin*base.Y
+			y = sin*base.X + cos*base.Y
+		)
+
+		player.Collisor.Vertices[i] = vector.Vector2{
+			X: (player.Pos.X + x*player.Scale),
+			Y: (player.Pos.Y - y*player.Scale),
+		}
+	}
+	player.hasCollider = true
+}
+
+// MoveToImpact moves the player back along this tick's motion, toi being the time of impact from collision.Sweep
+func (player *Player) MoveToImpact(toi float64) {
+	player.Pos = player.PrevPos.Lerp(&player.Pos, toi)
+	collision.LerpPolygon(&player.Collisor, player.PrevCollisor, player.Collisor, toi)
+}
+
+func (player *Player) DieByCollision() {
+	player.die(PlayerDiedByCollision)
+}
+
+func (player *Player) die(event PlayerEvent) {
+	player.Dead = true
+	player.eventHandler(event)
+}
+
+func (player *Player) GetDimensions() (float64, float64) {
+	return player.w * player.Scale, player.h * player.Scale
+}
+
+func (player *Player) GetTopLeftPos() vector.Vector2 {
+	w, h := player.GetDimensions()
+	return vector.Vector2{
+		X: player.Pos.X - w/2,
+		Y: player.Pos.Y - h/2,
+	}
+}
+
+func (player *Player) SetInput(source input.Source) {
+	player.input = source
+}
+
+func (player *Player) Update() {
+	var input input.State
+	if player.input != nil {
+		input = player.input.Poll()
+	}
+
+	player.Thrust = 0
+
+	if player.Dead {
+		return
+	}
+
+	if player.checkOob() {
+		return
+	}
+
+	if player.Scale <= player.MinimumScale {
+		player.die(PlayerDiedByShrinking)
+		return
+	}
+
+	sin, cos := math.Sincos(player.Rot)
+
+	// right after a reset the collider is still where the last attempt ended
+	if !player.hasCollider {
+		player.updateCollider(sin, cos)
+	}
+	player.PrevPos = player.Pos
+	copy(player.PrevCollisor.Vertices, player.Collisor.Vertices)
+
+	player.Rot += player.Maneuverability * input.SteerLeft
+	player.Rot -= player.Maneuverability * input.SteerRight
+
+	if input.Thrust > 0 {
+		// partial (analog) thrust deflates the ship proportionally, see scaleFactor below
+		player.Propulsion = input.Thrust
+		player.Thrust = input.Thrust
+	}
+
+	if player.Propulsion > 0.0 {
+		player.Acl.Set(player.Propulsion*cos, -player.Propulsion*sin)
+		player.Scale = max(0, player.Scale-(player.Propulsion*scaleFactor))
+	}
+
+	player.Vel.Add(player.Acl)
+	player.Pos.Add(player.Vel)
+	player.Acl.Set(0, 0)
+	player.Propulsion = constantPropulsion
+
+	player.Vel.Sub(player.Vel.MulScalar(airFriction))
+
+	player.updateCollider(sin, cos)
+}
+
+func (player *Player) GhostFrame() GhostFrame {
+	return GhostFrame{
+		Pos:   player.Pos,
+		Rot:   player.Rot,
+		Scale: player.Scale,
+	}
+}
+
+func (player *Player) checkOob() bool {
+	var (
+		pos    = player.Pos
+		bounds = player.bounds
+
+		horizontal = pos.X < bounds.Pos.X || pos.X >= bounds.Pos.X+bounds.W
+		vertical   = pos.Y < bounds.Pos.Y || pos.Y >= bounds.Pos.Y+bounds.H
+		isOob      = horizontal || vertical
+	)
+	if isOob {
+		player.die(PlayerDiedByOutOfBounds)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package simulation
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/abelroes/gmtk2024/src/collision"
+)
+
+// RaycastTarget is one or several of the Raycast* flags, telling which colliders a ray may hit
+type RaycastTarget int
+
+const (
+	RaycastWalls RaycastTarget = 1 << iota
+	RaycastObstacles
+	RaycastGoal
+	RaycastPlayer
+
+	RaycastEverything = RaycastWalls | RaycastObstacles | RaycastGoal | RaycastPlayer
+)
+
+type RaycastHit struct {
+	collision.RayHit
+	// Target is the single flag of what was hit
+	Target RaycastTarget
+	// Index is the wall's or obstacle's index in the level, 0 for the goal and the player
+	Index int
+}
+
+// raycast reports every collider of targets the ray hits, in no particular order, walls and obstacles going through the broad-phase
+func (sim *Simulation) raycast(ray collision.Ray, targets RaycastTarget, onHit func(RaycastHit)) {
+	if targets&(RaycastWalls|RaycastObstacles) != 0 {
+		sim.rayCandidates = sim.broadPhase.Query(ray.Bounds(), sim.rayCandidates[:0])
+		for _, id := range sim.rayCandidates {
+			if id < len(sim.walls) {
+				if targets&RaycastWalls == 0 {
+					continue
+				}
+				if hit, ok := collision.RaycastRect(ray, sim.walls[id].Collisor); ok {
+					onHit(RaycastHit{RayHit: hit, Target: RaycastWalls, Index: id})
+				}
+				continue
+			}
+
+			if targets&RaycastObstacles == 0 {
+				continue
+			}
+			// the outline is cast rather than the convex parts, whose inner edges would count as hits
+			index := id - len(sim.walls)
+			obstacle := sim.obstacles[index]
+			var (
+				hit collision.RayHit
+				ok  bool
+			)
+			if obstacle.Closed {
+				hit, ok = collision.RaycastPolygon(ray, collision.CollisionPolygon{Vertices: obstacle.Vertices})
+			} else {
+				hit, ok = collision.RaycastPolyline(ray, obstacle.Vertices)
+			}
+			if ok {
+				onHit(RaycastHit{RayHit: hit, Target: RaycastObstacles, Index: index})
+			}
+		}
+	}
+
+	if targets&RaycastGoal != 0 {
+		if hit, ok := collision.RaycastCircle(ray, sim.goal.Collider); ok {
+			onHit(RaycastHit{RayHit: hit, Target: RaycastGoal})
+		}
+	}
+
+	// the collider is only placed on the player by its first update after a reset
+	if targets&RaycastPlayer != 0 && sim.player.hasCollider {
+		if hit, ok := collision.RaycastPolygon(ray, sim.player.Collisor); ok {
+			onHit(RaycastHit{RayHit: hit, Target: RaycastPlayer})
+		}
+	}
+}
+
+// Raycast returns the first collider of targets the ray hits, for lasers and line of sight checks
+func (sim *Simulation) Raycast(ray collision.Ray, targets RaycastTarget) (RaycastHit, bool) {
+	var (
+		first RaycastHit
+		found bool
+	)
+	sim.raycast(ray, targets, func(hit RaycastHit) {
+		if !found || hit.Distance < first.Distance {
+			first, found = hit, true
+		}
+	})
+	return first, found
+}
+
+// RaycastAll returns every collider of targets the ray hits, once each at its first hit, nearest first
+func (sim *Simulation) RaycastAll(ray collision.Ray, targets RaycastTarget) []RaycastHit {
+	var hits []RaycastHit
+	sim.raycast(ray, targets, func(hit RaycastHit) {
+		hits = append(hits, hit)
+	})
+	slices.SortStableFunc(hits, func(a, b RaycastHit) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	return hits
+}
@@ -0,0 +1,143 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/abelroes/gmtk2024/assets/levels"
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+// raycastLevel lines up a wall, a rock, a polyline and the goal along y = 240
+var raycastLevel = levels.Level{
+	PlayerStartPos: vector.New(100, 400),
+	GoalPos:        vector.New(500, 240),
+	Walls:          []levels.WallInfo{{Pos: vector.New(200, 200), W: 20, H: 80}},
+	Obstacles: []levels.ObstacleInfo{
+		{Vertices: []vector.Vector2{{X: 300, Y: 200}, {X: 340, Y: 200}, {X: 340, Y: 280}, {X: 300, Y: 280}}, Closed: true},
+		{Vertices: []vector.Vector2{{X: 400, Y: 180}, {X: 400, Y: 300}}},
+	},
+}
+
+func newRaycastSim() *Simulation {
+	sim := New(testConfig)
+	sim.SetLevel(raycastLevel)
+	return sim
+}
+
+func TestRaycast(t *testing.T) {
+	sim := newRaycastSim()
+	across := collision.NewRay(vector.New(0, 240), vector.New(640, 240))
+
+	tests := []struct {
+		name    string
+		ray     collision.Ray
+		targets RaycastTarget
+		want    RaycastHit
+		hit     bool
+	}{
+		{"nearest of everything", across, RaycastEverything, RaycastHit{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(200, 240), Normal: vector.New(-1, 0), Distance: 200}}, true},
+		{"obstacles only", across, RaycastObstacles, RaycastHit{Target: RaycastObstacles, Index: 0, RayHit: collision.RayHit{Point: vector.New(300, 240), Normal: vector.New(-1, 0), Distance: 300}}, true},
+		{"goal only", across, RaycastGoal, RaycastHit{Target: RaycastGoal, RayHit: collision.RayHit{Point: vector.New(470, 240), Normal: vector.New(-1, 0), Distance: 470}}, true},
+		{
+			"along the polyline",
+			collision.NewRay(vector.New(400, 0), vector.New(400, 640)),
+			RaycastEverything,
+			RaycastHit{Target: RaycastObstacles, Index: 1, RayHit: collision.RayHit{Point: vector.New(400, 180), Normal: vector.New(0, -1), Distance: 180}},
+			true,
+		},
+		{
+			"along the wall's edge",
+			collision.NewRay(vector.New(0, 200), vector.New(640, 200)),
+			RaycastWalls,
+			RaycastHit{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(200, 200), Normal: vector.New(-1, 0), Distance: 200}},
+			true,
+		},
+		{"origin inside the wall", collision.NewRay(vector.New(210, 240), vector.New(640, 240)), RaycastEverything, RaycastHit{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(210, 240), Normal: vector.New(-1, 0), Distance: 0}}, true},
+		{"nothing", collision.NewRay(vector.New(0, 100), vector.New(640, 100)), RaycastEverything, RaycastHit{}, false},
+		{"player before its first update", collision.NewRay(vector.New(0, 400), vector.New(200, 400)), RaycastPlayer, RaycastHit{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, hit := sim.Raycast(test.ray, test.targets)
+			if hit != test.hit {
+				t.Fatalf("hit is %v, want %v (%+v)", hit, test.hit, got)
+			}
+			if hit && got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRaycastPlayer(t *testing.T) {
+	sim := newRaycastSim()
+	sim.Step()
+
+	hit, ok := sim.Raycast(collision.NewRay(vector.New(0, 400), vector.New(200, 400)), RaycastEverything)
+	if !ok || hit.Target != RaycastPlayer {
+		t.Fatalf("expected to hit the player, got %+v", hit)
+	}
+	// the test ship is 40x40 at half scale
+	if hit.Point.X < 89 || hit.Point.X > 91 || hit.Normal.X > -.99 {
+		t.Errorf("hit the player at %v with normal %v", hit.Point, hit.Normal)
+	}
+}
+
+func TestRaycastAll(t *testing.T) {
+	sim := newRaycastSim()
+	across := collision.NewRay(vector.New(0, 240), vector.New(640, 240))
+
+	tests := []struct {
+		name    string
+		ray     collision.Ray
+		targets RaycastTarget
+		want    []RaycastHit
+	}{
+		{
+			"nearest first",
+			across,
+			RaycastEverything,
+			[]RaycastHit{
+				{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(200, 240), Normal: vector.New(-1, 0), Distance: 200}},
+				{Target: RaycastObstacles, Index: 0, RayHit: collision.RayHit{Point: vector.New(300, 240), Normal: vector.New(-1, 0), Distance: 300}},
+				{Target: RaycastObstacles, Index: 1, RayHit: collision.RayHit{Point: vector.New(400, 240), Normal: vector.New(-1, 0), Distance: 400}},
+				{Target: RaycastGoal, RayHit: collision.RayHit{Point: vector.New(470, 240), Normal: vector.New(-1, 0), Distance: 470}},
+			},
+		},
+		{
+			"reversed",
+			collision.NewRay(vector.New(640, 240), vector.New(0, 240)),
+			RaycastWalls | RaycastGoal,
+			[]RaycastHit{
+				{Target: RaycastGoal, RayHit: collision.RayHit{Point: vector.New(530, 240), Normal: vector.New(1, 0), Distance: 110}},
+				{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(220, 240), Normal: vector.New(1, 0), Distance: 420}},
+			},
+		},
+		{
+			"too short for the goal",
+			collision.NewRay(vector.New(0, 240), vector.New(350, 240)),
+			RaycastEverything,
+			[]RaycastHit{
+				{Target: RaycastWalls, Index: 0, RayHit: collision.RayHit{Point: vector.New(200, 240), Normal: vector.New(-1, 0), Distance: 200}},
+				{Target: RaycastObstacles, Index: 0, RayHit: collision.RayHit{Point: vector.New(300, 240), Normal: vector.New(-1, 0), Distance: 300}},
+			},
+		},
+		{"nothing", collision.NewRay(vector.New(0, 100), vector.New(640, 100)), RaycastEverything, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := sim.RaycastAll(test.ray, test.targets)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d hits, want %d: %+v", len(got), len(test.want), got)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("hit %d is %+v, want %+v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
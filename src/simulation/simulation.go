@@ -0,0 +1,281 @@
+package simulation
+
+import (
+	"encoding/json"
+	"hash/fnv"
+
+	"github.com/abelroes/gmtk2024/assets/levels"
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+type Result struct {
+	Won  bool
+	Dead bool
+	// DeathCause is one of PlayerDiedBy*, only meaningful when Dead is true
+	DeathCause PlayerEvent
+	Scale      float64
+	Ticks      int
+}
+
+func (result Result) Finished() bool {
+	return result.Won || result.Dead
+}
+
+/*
+Config is what a simulation needs from the game's assets, as plain numbers so
+it runs without decoding a single image
+*/
+type Config struct {
+	// PlayerPolygon is the ship's hitbox around its center, at scale 1
+	PlayerPolygon []vector.Vector2
+	// PlayerW and PlayerH are the size of the ship's sprite at scale 1
+	PlayerW, PlayerH float64
+	GoalRadius       float64
+}
+
+/*
+Hash identifies everything a replay of level depends on besides its inputs:
+the level's layout and the ship's and goal's colliders. Two replays with the
+same inputs and hash play back exactly the same
+*/
+func (config Config) Hash(level levels.Level) uint64 {
+	// Name is left out, renaming a level doesn't change how it plays
+	level.Name = ""
+	data, err := json.Marshal(struct {
+		Config Config
+		Level  levels.Level
+	}{config, level})
+	if err != nil {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+/*
+Simulation holds the gameplay state of a single level (player, walls,
+obstacles, goal) and steps it. It only depends on the level data and the
+input sources, so it runs headless as well as inside the game's Engine
+*/
+type Simulation struct {
+	player    Player
+	walls     []Wall
+	obstacles []Obstacle
+	goal      Goal
+	config    Config
+	clock     *clock.Clock
+	result    Result
+	// broadPhase has the walls registered first, by index, then the obstacles
+	broadPhase *collision.Grid
+	candidates []int
+	// playerParts is the ship's hitbox decomposed once, placed on each pose the sweep tests
+	playerParts *collision.ConvexParts
+	// rayCandidates is kept apart from candidates so raycasts can run in the middle of a step
+	rayCandidates []int
+	// trajectory has one frame per tick of the current attempt, until it finishes
+	trajectory []GhostFrame
+
+	onPlayerEvent func(PlayerEvent)
+}
+
+// broadPhaseCellSize is about the size of the ship at its initial scale
+const broadPhaseCellSize = 128
+
+func New(config Config) *Simulation {
+	sim := &Simulation{
+		walls:       []Wall{},
+		config:      config,
+		clock:       clock.New(),
+		goal:        NewGoal(config.GoalRadius),
+		broadPhase:  collision.NewGrid(broadPhaseCellSize),
+		playerParts: collision.NewConvexParts(config.PlayerPolygon),
+	}
+	sim.player = NewPlayer(sim.clock, config.PlayerW, config.PlayerH, config.PlayerPolygon, sim.handlePlayerEvents)
+
+	return sim
+}
+
+func (sim *Simulation) SetOnPlayerEvent(onPlayerEvent func(PlayerEvent)) {
+	sim.onPlayerEvent = onPlayerEvent
+}
+
+func (sim *Simulation) SetInput(source input.Source) {
+	sim.player.SetInput(source)
+}
+
+func (sim *Simulation) Result() Result {
+	return sim.result
+}
+
+func (sim *Simulation) Trajectory() []GhostFrame {
+	return sim.trajectory
+}
+
+func (sim *Simulation) Config() Config {
+	return sim.config
+}
+
+func (sim *Simulation) Clock() *clock.Clock {
+	return sim.clock
+}
+
+func (sim *Simulation) Player() *Player {
+	return &sim.player
+}
+
+func (sim *Simulation) Walls() []Wall {
+	return sim.walls
+}
+
+func (sim *Simulation) Obstacles() []Obstacle {
+	return sim.obstacles
+}
+
+func (sim *Simulation) Goal() *Goal {
+	return &sim.goal
+}
+
+func (sim *Simulation) handlePlayerEvents(event PlayerEvent) {
+	sim.result.Dead = true
+	sim.result.DeathCause = event
+
+	if sim.onPlayerEvent != nil {
+		sim.onPlayerEvent(event)
+	}
+}
+
+func (sim *Simulation) SetLevel(level levels.Level) {
+	sim.result = Result{}
+	sim.trajectory = sim.trajectory[:0]
+
+	sim.player.Reset()
+	sim.player.Pos = level.PlayerStartPos
+	sim.player.Rot = level.PlayerStartRot
+	sim.goal.SetPos(level.GoalPos)
+
+	walls := make([]Wall, 0, len(level.Walls))
+
+	for _, wallInfo := range level.Walls {
+		var movement *WallMovement
+		if wallInfo.Movement != nil {
+			movement = &WallMovement{
+				Direction: wallInfo.Movement.Direction,
+				Speed:     wallInfo.Movement.Speed,
+				Cooldown:  wallInfo.Movement.Cooldown,
+			}
+		}
+
+		wall := NewWall(sim.clock, wallInfo.Pos.X, wallInfo.Pos.Y, wallInfo.W, wallInfo.H, wallInfo.Rotation, movement)
+		wall.FlipH, wall.FlipV = wallInfo.FlipH, wallInfo.FlipV
+		walls = append(walls, wall)
+	}
+
+	sim.walls = walls
+
+	obstacles := make([]Obstacle, 0, len(level.Obstacles))
+	for _, obstacleInfo := range level.Obstacles {
+		obstacles = append(obstacles, NewObstacle(obstacleInfo.Vertices, obstacleInfo.Closed))
+	}
+	sim.obstacles = obstacles
+
+	sim.broadPhase.Clear()
+	for _, wall := range sim.walls {
+		sim.broadPhase.Insert(wall.Collisor.Bounds())
+	}
+	for _, obstacle := range sim.obstacles {
+		sim.broadPhase.Insert(collision.CollisionPolygon{Vertices: obstacle.Vertices}.Bounds())
+	}
+}
+
+func (sim *Simulation) Step() {
+	running := !sim.result.Finished()
+	sim.clock.Step()
+
+	for i := range sim.walls {
+		if sim.walls[i].Movement != nil {
+			sim.walls[i].Update()
+			sim.broadPhase.Update(i, sim.walls[i].Collisor.Bounds())
+		}
+	}
+
+	sim.player.Update()
+	sim.goal.Update()
+	sim.collisionDetection()
+
+	if running {
+		sim.result.Ticks++
+		sim.trajectory = append(sim.trajectory, sim.player.GhostFrame())
+	}
+	sim.result.Scale = sim.player.Scale
+}
+
+/*
+collisionDetection sweeps the player from where it was at the start of the
+tick, so it can't go through a wall between two ticks. Walls and the goal are
+taken where they are at the end of the tick, and whichever is hit first wins
+*/
+func (sim *Simulation) collisionDetection() {
+	// a player that already won stays won, even if it keeps drifting into a wall
+	if sim.player.Dead || sim.result.Finished() {
+		return
+	}
+
+	sweptBounds := sim.player.PrevCollisor.Bounds().Union(sim.player.Collisor.Bounds())
+	sim.candidates = sim.broadPhase.Query(sweptBounds, sim.candidates[:0])
+
+	wallImpact, hitWall := collision.Sweep(sim.player.PrevCollisor, sim.player.Collisor, sim.hitsWall)
+	goalImpact, hitGoal := collision.Sweep(sim.player.PrevCollisor, sim.player.Collisor, func(polygon collision.CollisionPolygon) bool {
+		return collision.HasCollidedCirclePolygon(sim.goal.Collider, polygon)
+	})
+
+	switch {
+	case hitGoal && (!hitWall || goalImpact <= wallImpact):
+		sim.result.Won = true
+	case hitWall:
+		sim.player.MoveToImpact(wallImpact)
+		sim.player.DieByCollision()
+	}
+}
+
+// hitsWall runs the narrow-phase tests on the broad-phase candidates only
+func (sim *Simulation) hitsWall(polygon collision.CollisionPolygon) bool {
+	var parts []collision.CollisionPolygon
+	for _, id := range sim.candidates {
+		if id < len(sim.walls) {
+			if collision.HasCollidedRectPolygon(sim.walls[id].Collisor, polygon) {
+				return true
+			}
+			continue
+		}
+
+		if parts == nil {
+			parts = sim.playerParts.At(polygon)
+		}
+		if collision.HasCollidedPolygonParts(sim.obstacles[id-len(sim.walls)].Parts, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Simulate runs level headless for at most ticks steps, polling source once per
+tick, and stops as soon as the player wins or dies. Use input.NewReplay to
+feed a fixed input sequence
+*/
+func Simulate(config Config, level levels.Level, source input.Source, ticks int) Result {
+	sim := New(config)
+	sim.SetInput(source)
+	sim.SetLevel(level)
+
+	for i := 0; i < ticks && !sim.result.Finished(); i++ {
+		sim.Step()
+	}
+
+	return sim.Result()
+}
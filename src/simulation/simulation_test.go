@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/abelroes/gmtk2024/assets/levels"
+	"github.com/abelroes/gmtk2024/src/input"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+var testConfig = Config{
+	PlayerPolygon: []vector.Vector2{{X: -20, Y: -20}, {X: 20, Y: -20}, {X: 20, Y: 20}, {X: -20, Y: 20}},
+	PlayerW:       40,
+	PlayerH:       40,
+	GoalRadius:    40,
+}
+
+func fullThrust(int) input.State {
+	return input.State{Thrust: 1}
+}
+
+func TestSimulateReachesGoal(t *testing.T) {
+	level := levels.Level{
+		PlayerStartPos: vector.New(100, 240),
+		GoalPos:        vector.New(300, 240),
+	}
+
+	result := Simulate(testConfig, level, input.NewScript(fullThrust), 600)
+	if !result.Won || result.Dead {
+		t.Fatalf("expected a win, got %+v", result)
+	}
+}
+
+func TestSimulateDiesOnWall(t *testing.T) {
+	level := levels.Level{
+		PlayerStartPos: vector.New(100, 240),
+		GoalPos:        vector.New(300, 240),
+		Walls:          []levels.WallInfo{{Pos: vector.New(200, 140), W: 4, H: 200}},
+	}
+
+	result := Simulate(testConfig, level, input.NewScript(fullThrust), 600)
+	if result.Won || !result.Dead || result.DeathCause != PlayerDiedByCollision {
+		t.Fatalf("expected a collision death, got %+v", result)
+	}
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	level := levels.Level{
+		PlayerStartPos: vector.New(100, 240),
+		GoalPos:        vector.New(500, 100),
+		Obstacles: []levels.ObstacleInfo{{
+			Vertices: []vector.Vector2{{X: 250, Y: 150}, {X: 350, Y: 150}, {X: 300, Y: 250}},
+			Closed:   true,
+		}},
+	}
+	steer := func(tick int) input.State {
+		return input.State{SteerLeft: float64(tick%7) / 7, Thrust: float64(tick%3) / 2}
+	}
+
+	first := Simulate(testConfig, level, input.NewScript(steer), 600)
+	second := Simulate(testConfig, level, input.NewScript(steer), 600)
+	if first != second {
+		t.Fatalf("same inputs gave %+v then %+v", first, second)
+	}
+}
+
+func TestCollisionKillsOnce(t *testing.T) {
+	// the ship flies into overlapping obstacles and a wall in the same tick
+	level := levels.Level{
+		PlayerStartPos: vector.New(100, 240),
+		GoalPos:        vector.New(500, 240),
+		Walls:          []levels.WallInfo{{Pos: vector.New(200, 140), W: 40, H: 200}},
+		Obstacles: []levels.ObstacleInfo{
+			{Vertices: []vector.Vector2{{X: 190, Y: 100}, {X: 260, Y: 100}, {X: 260, Y: 380}, {X: 190, Y: 380}}, Closed: true},
+			{Vertices: []vector.Vector2{{X: 195, Y: 100}, {X: 195, Y: 380}}},
+		},
+	}
+
+	var events []PlayerEvent
+	sim := New(testConfig)
+	sim.SetOnPlayerEvent(func(event PlayerEvent) {
+		events = append(events, event)
+	})
+	sim.SetInput(input.NewScript(fullThrust))
+	sim.SetLevel(level)
+	for i := 0; i < 600; i++ {
+		sim.Step()
+	}
+
+	if len(events) != 1 || events[0] != PlayerDiedByCollision {
+		t.Fatalf("expected a single collision death, got %v", events)
+	}
+}
+
+func TestWinIsFinal(t *testing.T) {
+	// the wall is right behind the goal, the ship keeps flying into it after winning
+	level := levels.Level{
+		PlayerStartPos: vector.New(100, 240),
+		GoalPos:        vector.New(300, 240),
+		Walls:          []levels.WallInfo{{Pos: vector.New(360, 140), W: 40, H: 200}},
+	}
+
+	var events []PlayerEvent
+	sim := New(testConfig)
+	sim.SetOnPlayerEvent(func(event PlayerEvent) {
+		events = append(events, event)
+	})
+	sim.SetInput(input.NewScript(fullThrust))
+	sim.SetLevel(level)
+
+	for i := 0; i < 600 && !sim.Result().Won; i++ {
+		sim.Step()
+	}
+	if !sim.Result().Won {
+		t.Fatalf("expected a win, got %+v", sim.Result())
+	}
+	for i := 0; i < 30; i++ {
+		sim.Step()
+	}
+
+	if result := sim.Result(); result.Dead || len(events) != 0 {
+		t.Fatalf("the player died after winning: %+v, events %v", result, events)
+	}
+	if sim.Player().Pos.X < 360 {
+		t.Fatalf("the ship never reached the wall, it is at %v", sim.Player().Pos)
+	}
+}
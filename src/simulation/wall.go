@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"time"
+
+	"github.com/abelroes/gmtk2024/src/clock"
+	"github.com/abelroes/gmtk2024/src/collision"
+	"github.com/abelroes/gmtk2024/src/vector"
+)
+
+type Wall struct {
+	W, H         float64
+	FlipH, FlipV bool
+	// Pos is where the collider starts, moving walls go back and forth from it
+	Pos      vector.Vector2
+	Collisor collision.CollisionRect
+	Movement *WallMovement
+	clock    *clock.Clock
+}
+
+type WallMovementState byte
+
+const (
+	MovementGoing WallMovementState = iota
+	MovementGoingPause
+	MovementReturning
+	MovementReturningPause
+)
+
+type WallMovement struct {
+	Direction  vector.Vector2
+	Speed      float64
+	Cooldown   time.Duration
+	state      WallMovementState
+	pauseStart clock.Tick
+}
+
+// NewWall places a width x height wall with its top left corner at x, y and then rotates it around its center
+func NewWall(clock *clock.Clock, x, y, width, height, rotation float64, movement *WallMovement) Wall {
+	pos := vector.Vector2{X: x, Y: y}
+	return Wall{
+		Pos: pos,
+		W:   width, H: height,
+		Collisor: collision.CollisionRect{
+			Pos: pos,
+			W:   width, H: height,
+			Rotation: rotation,
+		},
+		Movement: movement,
+		clock:    clock,
+	}
+}
+
+func (wall *Wall) Update() {
+	if wall.Movement == nil {
+		return
+	}
+
+	dir := wall.Movement.Direction.Normalize()
+
+	switch wall.Movement.state {
+	case MovementGoing:
+		wall.Collisor.Pos.Add(dir.MulScalar(wall.Movement.Speed))
+
+		finalPos := wall.Pos.AddOut(wall.Movement.Direction)
+		if wall.Collisor.Pos.Distance(finalPos) <= 5 {
+			wall.Movement.state = MovementGoingPause
+			wall.Movement.pauseStart = wall.clock.Now()
+		}
+
+	case MovementGoingPause:
+		if wall.clock.Since(wall.Movement.pauseStart) >= clock.FromDuration(wall.Movement.Cooldown) {
+			wall.Movement.state = MovementReturning
+		}
+	case MovementReturning:
+		wall.Collisor.Pos.Add(dir.MulScalar(-wall.Movement.Speed))
+
+		startPos := wall.Pos
+		if wall.Collisor.Pos.Distance(startPos) <= 5 {
+			wall.Movement.pauseStart = wall.clock.Now()
+			wall.Movement.state = MovementReturningPause
+		}
+	case MovementReturningPause:
+		if wall.clock.Since(wall.Movement.pauseStart) >= clock.FromDuration(wall.Movement.Cooldown) {
+			wall.Movement.state = MovementGoing
+		}
+	}
+}
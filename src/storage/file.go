@@ -0,0 +1,50 @@
+//go:build !js
+
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type fileStorage struct {
+	dir string
+}
+
+func New() (Storage, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFileStorage(filepath.Join(configDir, appName)), nil
+}
+
+// NewFileStorage stores every key as a file inside dir
+func NewFileStorage(dir string) Storage {
+	return &fileStorage{dir: dir}
+}
+
+func (storage *fileStorage) Load(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(storage.dir, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (storage *fileStorage) Save(key string, data []byte) error {
+	if err := os.MkdirAll(storage.dir, 0o755); err != nil {
+		return err
+	}
+
+	// write then rename so a crash never leaves a half written file behind
+	path := filepath.Join(storage.dir, key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
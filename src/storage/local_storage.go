@@ -0,0 +1,48 @@
+//go:build js
+
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+type localStorage struct {
+	storage js.Value
+}
+
+func New() (Storage, error) {
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return nil, errors.New("localStorage is not available")
+	}
+
+	return &localStorage{storage: storage}, nil
+}
+
+func (storage *localStorage) key(key string) string {
+	return appName + "/" + key
+}
+
+func (storage *localStorage) Load(key string) ([]byte, error) {
+	value := storage.storage.Call("getItem", storage.key(key))
+	if value.IsNull() {
+		return nil, ErrNotFound
+	}
+
+	return base64.StdEncoding.DecodeString(value.String())
+}
+
+func (storage *localStorage) Save(key string, data []byte) (err error) {
+	// setItem throws when the quota is exceeded, which syscall/js turns into a panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("localStorage: %v", r)
+		}
+	}()
+
+	storage.storage.Call("setItem", storage.key(key), base64.StdEncoding.EncodeToString(data))
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import "errors"
+
+var ErrNotFound = errors.New("storage: key not found")
+
+/*
+Storage persists small blobs between sessions, as files under the user config
+dir on desktop and in the browser's localStorage on wasm
+*/
+type Storage interface {
+	// Load returns ErrNotFound when nothing was saved under key yet
+	Load(key string) ([]byte, error)
+	Save(key string, data []byte) error
+}
+
+const appName = "space-deflation"
+
+type prefixedStorage struct {
+	store  Storage
+	prefix string
+}
+
+// WithPrefix keeps the keys of store apart from everything else saved in it, store may be nil
+func WithPrefix(store Storage, prefix string) Storage {
+	if store == nil {
+		return nil
+	}
+	return &prefixedStorage{store: store, prefix: prefix}
+}
+
+func (storage *prefixedStorage) Load(key string) ([]byte, error) {
+	return storage.store.Load(storage.prefix + key)
+}
+
+func (storage *prefixedStorage) Save(key string, data []byte) error {
+	return storage.store.Save(storage.prefix+key, data)
+}
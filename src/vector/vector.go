@@ -71,6 +71,12 @@ func (v *Vector2) Sub(other Vector2) {
 	v.Y -= other.Y
 }
 
+func (v *Vector2) SubOut(other Vector2) Vector2 {
+	ans := *v
+	ans.Sub(other)
+	return ans
+}
+
 func (v *Vector2) SubScalar(scalar float64) Vector2 {
 	return New(v.X-scalar, v.Y-scalar)
 }